@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"time"
 
@@ -21,16 +22,24 @@ type Client interface {
 	// Call 同步调用远程服务
 	Call(ctx context.Context, service, method string, req interface{}, resp interface{}) error
 
+	// CallAsync 异步调用远程服务，立即返回Future，调用完成前不应访问resp
+	CallAsync(ctx context.Context, service, method string, req interface{}, resp interface{}) *Future
+
 	// Close 关闭客户端连接
 	Close() error
 }
 
 // ClientOptions 客户端配置选项
 type ClientOptions struct {
-	Address       string        // 服务器地址
-	Timeout       time.Duration // 请求超时
-	PoolSize      int           // 连接池大小
-	SerializeType uint8         // 序列化类型
+	Address         string                  // 服务器地址
+	Timeout         time.Duration           // 请求超时
+	PoolSize        int                     // 连接池大小
+	SerializeType   uint8                   // 序列化类型
+	CompressType    uint8                   // 压缩类型，默认不压缩
+	CompressMinSize int                     // 启用压缩的最小消息体大小(字节)
+	Interceptors    []rpc.ClientInterceptor // 客户端拦截器，按顺序由外向内执行
+	TLSConfig       *tls.Config             // TLS配置，为nil时使用明文连接；可以使用rpc.NewClientTLSConfig创建
+	Credentials     rpc.PerRPCCredentials   // 每次调用附加的认证信息
 }
 
 // 默认客户端配置
@@ -57,7 +66,30 @@ func NewClient(options *ClientOptions) (Client, error) {
 		return nil, ErrNoAddress
 	}
 
-	pool := rpc.NewConnPool(options.Address, options.PoolSize, time.Minute*5)
+	// 未指定序列化类型时使用JSON
+	serializeType := options.SerializeType
+	if serializeType == 0 {
+		serializeType = protocol.SerializationTypeJSON
+	}
+
+	clientOpts := []rpc.ClientOption{rpc.WithSerialization(serializeType)}
+	if options.CompressType != protocol.CompressTypeNone {
+		clientOpts = append(clientOpts, rpc.WithCompression(options.CompressType, options.CompressMinSize))
+	}
+	if options.TLSConfig != nil {
+		clientOpts = append(clientOpts, rpc.WithTLSConfig(options.TLSConfig))
+	}
+	if options.Credentials != nil {
+		clientOpts = append(clientOpts, rpc.WithPerRPCCredentials(options.Credentials))
+	}
+	if len(options.Interceptors) > 0 {
+		clientOpts = append(clientOpts, rpc.WithInterceptors(options.Interceptors...))
+	}
+
+	pool := rpc.NewConnPool(options.Address, options.PoolSize, time.Minute*5,
+		rpc.WithMaxActive(options.PoolSize),
+		rpc.WithClientOptions(clientOpts...),
+	)
 
 	return &simpleClient{
 		pool:    pool,
@@ -82,26 +114,24 @@ func (c *simpleClient) Call(ctx context.Context, service, method string, req int
 		defer cancel()
 	}
 
-	// 从连接池获取连接
-	client, err := c.pool.Get()
+	// 从连接池获取连接，连接数达到上限时等待直到超时
+	client, err := c.pool.GetContext(ctx)
 	if err != nil {
 		return err
 	}
 	defer c.pool.Put(client)
 
-	// 执行调用
-	data, err := client.Call(service, method, req)
-	if err != nil {
-		return err
-	}
-
-	// 反序列化响应
-	decoder := protocol.GetCodecByType(c.options.SerializeType)
-	if decoder == nil {
-		return errors.New("no available decoder")
-	}
+	// 执行调用，响应按请求使用的序列化类型解码
+	return client.Invoke(ctx, service, method, req, resp)
+}
 
-	return decoder.Decode(data, resp)
+// CallAsync 实现异步调用，可以同时向多个服务发起调用后统一等待结果
+func (c *simpleClient) CallAsync(ctx context.Context, service, method string, req interface{}, resp interface{}) *Future {
+	future := newFuture()
+	go func() {
+		future.complete(c.Call(ctx, service, method, req, resp))
+	}()
+	return future
 }
 
 // Close 关闭客户端
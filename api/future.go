@@ -0,0 +1,51 @@
+package api
+
+import "context"
+
+// Future 异步调用的结果
+type Future struct {
+	done chan struct{}
+	err  error
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+// complete 记录调用结果并唤醒等待方
+func (f *Future) complete(err error) {
+	f.err = err
+	close(f.done)
+}
+
+// Done 返回调用完成时关闭的通道
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait 等待调用完成并返回调用的错误，成功时响应已写入发起调用时传入的resp
+func (f *Future) Wait() error {
+	<-f.done
+	return f.err
+}
+
+// WaitContext 等待调用完成或ctx结束，ctx结束时返回ctx的错误，调用本身仍会在其超时后结束
+func (f *Future) WaitContext(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitAll 等待所有调用完成，返回第一个失败调用的错误
+func WaitAll(futures ...*Future) error {
+	var firstErr error
+	for _, f := range futures {
+		if err := f.Wait(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
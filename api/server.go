@@ -1,7 +1,11 @@
 package api
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"net"
+	"time"
 
 	"github.com/fyerfyer/fyer-rpc/protocol"
 	"github.com/fyerfyer/fyer-rpc/registry/etcd"
@@ -14,6 +18,9 @@ type Server interface {
 	// Register 注册服务
 	Register(service interface{}) error
 
+	// RegisterName 以指定的名称和版本注册服务，为空时使用服务信息或类型名称
+	RegisterName(name, version string, service interface{}) error
+
 	// Start 启动服务器
 	Start() error
 
@@ -26,12 +33,24 @@ type Server interface {
 
 // ServerOptions 服务器配置选项
 type ServerOptions struct {
-	Address        string   // 服务监听地址，默认":8000"
-	SerializeType  uint8    // 序列化类型，默认JSON
-	EnableRegistry bool     // 是否启用服务注册
-	RegistryAddrs  []string // 注册中心地址
-	ServiceName    string   // 服务名称
-	ServiceVersion string   // 服务版本
+	Address         string   // 服务监听地址，默认":8000"
+	SerializeType   uint8    // 序列化类型，默认JSON
+	CompressType    uint8    // 响应压缩类型，默认不压缩
+	CompressMinSize int      // 启用压缩的最小消息体大小(字节)
+	EnableRegistry  bool     // 是否启用服务注册
+	RegistryAddrs   []string // 注册中心地址
+	ServiceName     string   // 服务名称
+	ServiceVersion  string   // 服务版本
+	// 优雅关闭时等待处理中请求的最长时间，为0时使用默认值
+	ShutdownTimeout time.Duration
+	// 服务端拦截器，按顺序由外向内执行
+	Interceptors []rpc.ServerInterceptor
+	// TLS配置，为nil时使用明文连接；可以使用rpc.NewServerTLSConfig根据证书文件创建
+	TLSConfig *tls.Config
+	// 请求认证器，为nil时不认证；内置rpc.NewTokenAuthenticator和rpc.NewHMACAuthenticator
+	Authenticator rpc.Authenticator
+	// 不注册内置的反射服务
+	DisableReflection bool
 }
 
 // 默认服务器配置
@@ -58,7 +77,17 @@ func NewServer(options *ServerOptions) Server {
 		options = DefaultServerOptions
 	}
 
-	server := rpc.NewServer()
+	serverOpts := []rpc.ServerOption{
+		rpc.WithServerCompression(options.CompressType, options.CompressMinSize),
+		rpc.WithShutdownTimeout(options.ShutdownTimeout),
+		rpc.WithServerInterceptors(options.Interceptors...),
+		rpc.WithServerTLSConfig(options.TLSConfig),
+		rpc.WithAuthenticator(options.Authenticator),
+	}
+	if options.DisableReflection {
+		serverOpts = append(serverOpts, rpc.WithoutReflection())
+	}
+	server := rpc.NewServer(serverOpts...)
 	server.SetSerializationType(options.SerializeType)
 
 	return &simpleServer{
@@ -72,6 +101,11 @@ func (s *simpleServer) Register(service interface{}) error {
 	return s.server.RegisterService(service)
 }
 
+// RegisterName 以指定的名称和版本注册服务
+func (s *simpleServer) RegisterName(name, version string, service interface{}) error {
+	return s.server.RegisterName(name, version, service)
+}
+
 // Start 启动服务器
 func (s *simpleServer) Start() error {
 	if s.started {
@@ -94,7 +128,7 @@ func (s *simpleServer) Start() error {
 	// 不创建监听器，直接让底层Server去创建
 	s.started = true
 	go func() {
-		if err := s.server.Start(s.options.Address); err != nil {
+		if err := s.server.Start(s.options.Address); err != nil && !errors.Is(err, rpc.ErrServerClosed) {
 			utils.Error("Server error: %v", err)
 		}
 	}()
@@ -116,12 +150,9 @@ func (s *simpleServer) Stop() error {
 		s.registry.Close()
 	}
 
-	// 通知底层服务器停止
+	// 优雅关闭底层服务器，等待处理中的请求完成
 	if s.server != nil {
-		// 如果底层Server有停止方法，调用它
-		if stopServer, ok := interface{}(s.server).(interface{ Stop() error }); ok {
-			return stopServer.Stop()
-		}
+		return s.server.Shutdown(context.Background())
 	}
 
 	return nil
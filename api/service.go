@@ -6,22 +6,15 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/fyerfyer/fyer-rpc/rpc"
 	"github.com/fyerfyer/fyer-rpc/utils"
 )
 
 // ServiceInfo 服务信息结构体，用于描述RPC服务
-type ServiceInfo struct {
-	Name        string            // 服务名称
-	Version     string            // 服务版本
-	Description string            // 服务描述
-	Metadata    map[string]string // 服务元数据
-}
+type ServiceInfo = rpc.ServiceInfo
 
-// Service RPC服务接口，用户可以选择实现该接口
-type Service interface {
-	// ServiceInfo 返回服务信息
-	ServiceInfo() *ServiceInfo
-}
+// Service RPC服务接口，用户可以选择实现该接口，注册时使用其返回的名称和版本
+type Service = rpc.Service
 
 var (
 	// ErrServiceNotPointer 服务不是指针类型错误
@@ -69,7 +62,7 @@ func ExtractServiceInfo(service interface{}) (*ServiceInfo, error) {
 
 	return &ServiceInfo{
 		Name:     serviceName,
-		Version:  "1.0.0", // 默认版本
+		Version:  rpc.DefaultServiceVersion,
 		Metadata: make(map[string]string),
 	}, nil
 }
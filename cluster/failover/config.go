@@ -2,6 +2,8 @@ package failover
 
 import (
 	"time"
+
+	"github.com/fyerfyer/fyer-rpc/transport"
 )
 
 // Config 故障转移配置
@@ -22,11 +24,12 @@ type Config struct {
 	HalfOpenSuccessThreshold float64       // 半开状态下成功率阈值(0-1)
 
 	// 故障检测配置
-	FailureDetectionTime time.Duration // 故障检测时间窗口
-	FailureThreshold     int           // 故障阈值次数
-	SuccessThreshold     int           // 成功阈值次数
-	ConnectionTimeout    time.Duration // 连接超时时间
-	RequestTimeout       time.Duration // 请求超时时间
+	FailureDetectionTime time.Duration    // 故障检测时间窗口
+	FailureThreshold     int              // 故障阈值次数
+	SuccessThreshold     int              // 成功阈值次数
+	ConnectionTimeout    time.Duration    // 连接超时时间
+	RequestTimeout       time.Duration    // 请求超时时间
+	Dialer               transport.Dialer // 探测实例时使用的连接方式，为nil时按地址的传输方式连接
 
 	// 恢复策略配置
 	RecoveryInterval  time.Duration // 恢复检查间隔
@@ -188,6 +191,13 @@ func WithTimeouts(connTimeout, reqTimeout time.Duration) Option {
 	}
 }
 
+// WithDialer 设置探测实例时使用的连接方式
+func WithDialer(dialer transport.Dialer) Option {
+	return func(c *Config) {
+		c.Dialer = dialer
+	}
+}
+
 // WithRecoveryStrategy 设置恢复策略
 func WithRecoveryStrategy(strategy string, interval time.Duration) Option {
 	return func(c *Config) {
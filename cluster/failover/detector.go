@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/fyerfyer/fyer-rpc/naming"
+	"github.com/fyerfyer/fyer-rpc/transport"
 )
 
 // BaseDetector 基础故障检测器
@@ -121,8 +122,7 @@ func (d *TimeoutDetector) Detect(ctx context.Context, instance *naming.Instance)
 	defer cancel()
 
 	// 尝试连接实例
-	var dialer net.Dialer
-	conn, err := dialer.DialContext(timeoutCtx, "tcp", instance.Address)
+	conn, err := dial(timeoutCtx, d.config, instance.Address)
 	if err != nil {
 		// 连接失败，标记失败
 		d.MarkFailed(ctx, instance)
@@ -348,9 +348,8 @@ func NewDetector(config *Config, detectorType string) Detector {
 		return NewErrorRateDetector(config, 100)
 	case "health_check":
 		return NewHealthCheckDetector(config, func(ctx context.Context, instance *naming.Instance) (bool, error) {
-			// 默认的健康检查实现：尝试建立TCP连接
-			var dialer net.Dialer
-			conn, err := dialer.DialContext(ctx, "tcp", instance.Address)
+			// 默认的健康检查实现：按配置的连接方式尝试建立连接
+			conn, err := dial(ctx, config, instance.Address)
 			if err != nil {
 				return false, err
 			}
@@ -362,3 +361,11 @@ func NewDetector(config *Config, detectorType string) Detector {
 		return NewTimeoutDetector(config)
 	}
 }
+
+// dial 连接实例，配置了Dialer时使用它，否则按地址的传输方式连接
+func dial(ctx context.Context, config *Config, address string) (net.Conn, error) {
+	if config.Dialer != nil {
+		return config.Dialer.Dial(ctx, address)
+	}
+	return transport.Dial(ctx, address)
+}
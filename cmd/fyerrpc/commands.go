@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/fyerfyer/fyer-rpc/rpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// list 通过反射服务列出服务，指定服务名时同时输出请求和响应的结构
+func list(ctx context.Context, opts *options, service string, stdout io.Writer) error {
+	client, err := dial(ctx, opts, service)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	ctx = outgoingContext(ctx, opts)
+
+	if service != "" {
+		schema, err := describe(ctx, client, service, opts.version)
+		if err != nil {
+			return err
+		}
+		printService(stdout, schema, true)
+		return nil
+	}
+
+	var resp rpc.ListServicesResponse
+	err = client.Invoke(ctx, rpc.ReflectionServiceName, "ListServices", &rpc.ListServicesRequest{}, &resp,
+		rpc.WithCallSerialization(protocol.SerializationTypeJSON))
+	if err != nil {
+		return fmt.Errorf("failed to list services: %w", err)
+	}
+	for _, schema := range resp.Services {
+		printService(stdout, schema, false)
+	}
+	return nil
+}
+
+// call 以JSON请求体调用方法并输出响应
+// 使用Protobuf序列化时根据反射服务返回的消息描述符构造动态消息
+func call(ctx context.Context, opts *options, target, body string, stdin io.Reader, stdout io.Writer) error {
+	dot := strings.LastIndex(target, ".")
+	if dot <= 0 || dot == len(target)-1 {
+		return fmt.Errorf("%w: method must be service.Method, got %q", errUsage, target)
+	}
+	service, method := target[:dot], target[dot+1:]
+
+	data, err := readBody(body, stdin)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("%w: request body is not valid JSON", errUsage)
+	}
+
+	client, err := dial(ctx, opts, service)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	ctx = outgoingContext(ctx, opts)
+	callOpts := []rpc.CallOption{rpc.WithVersion(opts.version)}
+
+	serializationType, _ := parseSerializer(opts.serializer)
+	if serializationType == protocol.SerializationTypeJSON {
+		var reply []byte
+		if err := client.Invoke(ctx, service, method, json.RawMessage(data), &reply, callOpts...); err != nil {
+			return err
+		}
+		return printJSON(stdout, reply, opts.raw)
+	}
+
+	schema, err := describe(ctx, client, service, opts.version)
+	if err != nil {
+		return err
+	}
+	var methodSchema *rpc.MethodSchema
+	for _, m := range schema.Methods {
+		if m.Name == method {
+			methodSchema = m
+		}
+	}
+	if methodSchema == nil {
+		return rpc.NewRPCError(rpc.ErrCodeNotFound, fmt.Sprintf("method not found: %s", method))
+	}
+	if methodSchema.Kind != rpc.MethodKindUnary {
+		return fmt.Errorf("%s is a %s method, only unary methods can be called", target, methodSchema.Kind)
+	}
+
+	reqDesc, err := messageDescriptor(methodSchema.Request)
+	if err != nil {
+		return err
+	}
+	req := dynamicpb.NewMessage(reqDesc)
+	if err := protojson.Unmarshal(data, req); err != nil {
+		return fmt.Errorf("%w: invalid request for %s: %v", errUsage, reqDesc.FullName(), err)
+	}
+
+	// 只返回error的方法没有响应
+	if methodSchema.Response == nil {
+		return client.Invoke(ctx, service, method, req, nil, callOpts...)
+	}
+	respDesc, err := messageDescriptor(methodSchema.Response)
+	if err != nil {
+		return err
+	}
+	resp := dynamicpb.NewMessage(respDesc)
+	if err := client.Invoke(ctx, service, method, req, resp, callOpts...); err != nil {
+		return err
+	}
+	return printProto(stdout, resp, opts.raw)
+}
+
+// describe 通过反射服务查询服务描述，反射服务始终使用JSON序列化
+func describe(ctx context.Context, client *rpc.Client, service, version string) (*rpc.ServiceSchema, error) {
+	var schema rpc.ServiceSchema
+	err := client.Invoke(ctx, rpc.ReflectionServiceName, "DescribeService",
+		&rpc.DescribeServiceRequest{Name: service, Version: version}, &schema,
+		rpc.WithCallSerialization(protocol.SerializationTypeJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe %s: %w", service, err)
+	}
+	return &schema, nil
+}
+
+// messageDescriptor 从类型描述携带的FileDescriptorSet中解析消息描述符
+func messageDescriptor(schema *rpc.TypeSchema) (protoreflect.MessageDescriptor, error) {
+	if schema == nil || schema.ProtoName == "" || len(schema.Descriptor) == 0 {
+		name := "<none>"
+		if schema != nil {
+			name = schema.Name
+		}
+		return nil, fmt.Errorf("type %s is not a protobuf message, use -serializer json", name)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(schema.Descriptor, set); err != nil {
+		return nil, fmt.Errorf("invalid descriptor of %s: %w", schema.ProtoName, err)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor of %s: %w", schema.ProtoName, err)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(schema.ProtoName))
+	if err != nil {
+		return nil, fmt.Errorf("message %s not found in descriptor: %w", schema.ProtoName, err)
+	}
+	md, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message", schema.ProtoName)
+	}
+	return md, nil
+}
+
+// printJSON 输出JSON响应，响应为空时不输出
+func printJSON(w io.Writer, data []byte, raw bool) error {
+	if len(data) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	if raw {
+		if err := json.Compact(&buf, data); err != nil {
+			buf.Reset()
+			buf.Write(data)
+		}
+	} else if err := json.Indent(&buf, data, "", "  "); err != nil {
+		buf.Reset()
+		buf.Write(data)
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// printProto 以protojson格式输出Protobuf响应
+func printProto(w io.Writer, msg proto.Message, raw bool) error {
+	marshaler := protojson.MarshalOptions{Multiline: true, Indent: "  "}
+	if raw {
+		marshaler = protojson.MarshalOptions{}
+	}
+	data, err := marshaler.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to format response: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
@@ -0,0 +1,259 @@
+// fyerrpc 命令行客户端，通过服务器内置的反射服务列出服务，并以JSON请求体发起临时调用
+//
+// 用法:
+//
+//	fyerrpc [flags] list [service]
+//	fyerrpc [flags] call service.Method ['{json}' | -]
+//
+// 服务器地址通过 -addr 指定，或通过 -etcd 和 -discover 从注册中心发现
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/naming"
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/fyerfyer/fyer-rpc/registry/etcd"
+	"github.com/fyerfyer/fyer-rpc/rpc"
+	"github.com/fyerfyer/fyer-rpc/utils"
+)
+
+// 退出码
+const (
+	exitOK      = 0 // 调用成功
+	exitFailure = 1 // 连接或调用失败
+	exitUsage   = 2 // 命令行参数错误
+)
+
+const usage = `Usage:
+  fyerrpc [flags] list [service]
+  fyerrpc [flags] call service.Method ['{json}' | -]
+
+Commands:
+  list    list services and methods via server reflection; with a service name, also print request and response schemas
+  call    call a method with a JSON request body, read from stdin when the body is "-" (default "{}")
+
+Flags:
+`
+
+// errUsage 命令行参数错误
+var errUsage = errors.New("invalid usage")
+
+// headers 可重复的 -H key=value 参数
+type headers []string
+
+func (h *headers) String() string {
+	return strings.Join(*h, ",")
+}
+
+func (h *headers) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("header %q must be key=value", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+// options 命令行参数
+type options struct {
+	addr            string
+	etcdEndpoints   string
+	discover        string
+	discoverVersion string
+	serializer      string
+	timeout         time.Duration
+	version         string
+	headers         headers
+	tls             bool
+	caFile          string
+	certFile        string
+	keyFile         string
+	serverName      string
+	insecure        bool
+	raw             bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 执行命令并返回退出码
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	opts := &options{}
+	fs := flag.NewFlagSet("fyerrpc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.addr, "addr", "", `server address, e.g. "localhost:8000" or "unix:///tmp/rpc.sock" (default "localhost:8000" without -etcd)`)
+	fs.StringVar(&opts.etcdEndpoints, "etcd", "", "comma separated etcd endpoints used to discover the server")
+	fs.StringVar(&opts.discover, "discover", "", "service name registered in etcd (default: the called service)")
+	fs.StringVar(&opts.discoverVersion, "discover-version", rpc.DefaultServiceVersion, "service version registered in etcd")
+	fs.StringVar(&opts.serializer, "serializer", "json", "request serializer: json or protobuf")
+	fs.DurationVar(&opts.timeout, "timeout", 10*time.Second, "timeout of the whole command")
+	fs.StringVar(&opts.version, "version", "", "service version to call (default: the server's default version)")
+	fs.Var(&opts.headers, "H", "request metadata as key=value, repeatable")
+	fs.BoolVar(&opts.tls, "tls", false, "use TLS")
+	fs.StringVar(&opts.caFile, "cacert", "", "CA certificate used to verify the server, implies -tls")
+	fs.StringVar(&opts.certFile, "cert", "", "client certificate for mutual TLS, implies -tls")
+	fs.StringVar(&opts.keyFile, "key", "", "client private key for mutual TLS")
+	fs.StringVar(&opts.serverName, "servername", "", "server name used to verify the server certificate")
+	fs.BoolVar(&opts.insecure, "insecure", false, "skip server certificate verification, implies -tls")
+	fs.BoolVar(&opts.raw, "raw", false, "print responses without indentation")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	// 命令行工具只向标准错误输出错误日志，避免干扰响应输出
+	utils.SetDefaultLogger(utils.NewLogger(utils.ErrorLevel, stderr))
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+
+	var err error
+	rest := fs.Args()
+	switch {
+	case len(rest) == 0:
+		err = fmt.Errorf("%w: missing command", errUsage)
+	case rest[0] == "list" && len(rest) <= 2:
+		service := ""
+		if len(rest) == 2 {
+			service = rest[1]
+		}
+		err = list(ctx, opts, service, stdout)
+	case rest[0] == "call" && (len(rest) == 2 || len(rest) == 3):
+		body := "{}"
+		if len(rest) == 3 {
+			body = rest[2]
+		}
+		err = call(ctx, opts, rest[1], body, stdin, stdout)
+	default:
+		err = fmt.Errorf("%w: unknown command %q", errUsage, strings.Join(rest, " "))
+	}
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "fyerrpc: %v\n\n", err)
+		fs.Usage()
+		return exitUsage
+	default:
+		printError(stderr, err)
+		return exitFailure
+	}
+}
+
+// dial 连接服务器，未指定地址时从etcd发现服务实例
+func dial(ctx context.Context, opts *options, service string) (*rpc.Client, error) {
+	serializationType, err := parseSerializer(opts.serializer)
+	if err != nil {
+		return nil, err
+	}
+
+	address := opts.addr
+	if address == "" && opts.etcdEndpoints != "" {
+		address, err = discover(ctx, opts, service)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if address == "" {
+		address = "localhost:8000"
+	}
+
+	clientOpts := []rpc.ClientOption{rpc.WithSerialization(serializationType)}
+	if opts.tls || opts.caFile != "" || opts.certFile != "" || opts.insecure {
+		tlsConfig, err := rpc.NewClientTLSConfig(&rpc.TLSConfig{
+			CertFile:           opts.certFile,
+			KeyFile:            opts.keyFile,
+			CAFile:             opts.caFile,
+			ServerName:         opts.serverName,
+			InsecureSkipVerify: opts.insecure,
+		})
+		if err != nil {
+			return nil, err
+		}
+		clientOpts = append(clientOpts, rpc.WithTLSConfig(tlsConfig))
+	}
+
+	client, err := rpc.NewClient(address, clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	return client, nil
+}
+
+// discover 从etcd获取一个可用的服务实例地址
+func discover(ctx context.Context, opts *options, service string) (string, error) {
+	name := opts.discover
+	if name == "" {
+		name = service
+	}
+	if name == "" {
+		return "", fmt.Errorf("%w: -discover is required to list services from etcd", errUsage)
+	}
+
+	registry, err := etcd.New(
+		etcd.WithEndpoints(strings.Split(opts.etcdEndpoints, ",")),
+		etcd.WithDialTimeout(opts.timeout),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to etcd: %w", err)
+	}
+	defer registry.Close()
+
+	instances, err := registry.ListServices(ctx, name, opts.discoverVersion)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover %s: %w", name, err)
+	}
+	for _, instance := range instances {
+		if instance.Status == naming.StatusEnabled {
+			return instance.Address, nil
+		}
+	}
+	return "", fmt.Errorf("no available instance of %s version %s", name, opts.discoverVersion)
+}
+
+func parseSerializer(name string) (uint8, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return protocol.SerializationTypeJSON, nil
+	case "protobuf", "proto":
+		return protocol.SerializationTypeProtobuf, nil
+	}
+	return 0, fmt.Errorf("%w: unknown serializer %q", errUsage, name)
+}
+
+// outgoingContext 将 -H 参数附加为请求元数据
+func outgoingContext(ctx context.Context, opts *options) context.Context {
+	for _, header := range opts.headers {
+		key, value, _ := strings.Cut(header, "=")
+		ctx = rpc.AppendToOutgoingContext(ctx, strings.TrimSpace(key), value)
+	}
+	return ctx
+}
+
+// readBody 读取请求体，"-"表示从标准输入读取
+func readBody(body string, stdin io.Reader) ([]byte, error) {
+	if body != "-" {
+		return []byte(body), nil
+	}
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request from stdin: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/fyerfyer/fyer-rpc/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type GreetRequest struct {
+	Name string `json:"name"`
+}
+
+type GreetResponse struct {
+	Message string `json:"message"`
+}
+
+// GreeterService JSON序列化的测试服务
+type GreeterService struct{}
+
+func (s *GreeterService) Greet(ctx context.Context, req *GreetRequest) (*GreetResponse, error) {
+	if req.Name == "" {
+		return nil, rpc.NewRPCError(rpc.ErrCodeInvalidParam, "name is required")
+	}
+	prefix := "hello"
+	if md, ok := rpc.IncomingMetadata(ctx); ok && md.Extra["lang"] == "fr" {
+		prefix = "bonjour"
+	}
+	return &GreetResponse{Message: prefix + " " + req.Name}, nil
+}
+
+// StructService Protobuf序列化的测试服务
+type StructService struct{}
+
+func (s *StructService) Keys(ctx context.Context, req *structpb.Struct) (*structpb.ListValue, error) {
+	keys := &structpb.ListValue{}
+	for key := range req.Fields {
+		keys.Values = append(keys.Values, structpb.NewStringValue(key))
+	}
+	return keys, nil
+}
+
+func startServer(t *testing.T) string {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterService(&GreeterService{}))
+	require.NoError(t, server.RegisterService(&StructService{}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Shutdown(context.Background()) })
+	return listener.Addr().String()
+}
+
+func execute(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(`{"name":"stdin"}`), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCLI(t *testing.T) {
+	addr := startServer(t)
+
+	t.Run("list", func(t *testing.T) {
+		code, out, _ := execute("-addr", addr, "list")
+		require.Equal(t, exitOK, code)
+		assert.Contains(t, out, "GreeterService 1.0.0\n  Greet(fyerrpc.GreetRequest) returns (fyerrpc.GreetResponse)\n")
+		assert.Contains(t, out, "Keys(google.protobuf.Struct) returns (google.protobuf.ListValue)")
+		assert.Contains(t, out, "Reflection 1.0.0")
+	})
+
+	t.Run("list service", func(t *testing.T) {
+		code, out, _ := execute("-addr", addr, "list", "GreeterService")
+		require.Equal(t, exitOK, code)
+		assert.Contains(t, out, "    request fyerrpc.GreetRequest {\n      name string\n    }\n")
+	})
+
+	t.Run("call", func(t *testing.T) {
+		code, out, _ := execute("-addr", addr, "call", "GreeterService.Greet", `{"name":"fyer"}`)
+		require.Equal(t, exitOK, code)
+		assert.Equal(t, "{\n  \"message\": \"hello fyer\"\n}\n", out)
+	})
+
+	t.Run("stdin and headers", func(t *testing.T) {
+		code, out, _ := execute("-addr", addr, "-H", "lang=fr", "-raw", "call", "GreeterService.Greet", "-")
+		require.Equal(t, exitOK, code)
+		assert.Equal(t, "{\"message\":\"bonjour stdin\"}\n", out)
+	})
+
+	t.Run("protobuf", func(t *testing.T) {
+		code, out, stderr := execute("-addr", addr, "-serializer", "protobuf", "-raw", "call", "StructService.Keys", `{"a":1}`)
+		require.Equal(t, exitOK, code, stderr)
+		assert.Equal(t, "[\"a\"]\n", out)
+	})
+
+	t.Run("structured error", func(t *testing.T) {
+		code, _, stderr := execute("-addr", addr, "call", "GreeterService.Greet", `{}`)
+		assert.Equal(t, exitFailure, code)
+		assert.Equal(t, "Error: invalid parameter (code 1001)\nMessage: name is required\n", stderr)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		code, _, stderr := execute("-addr", addr, "call", "Greet")
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "method must be service.Method")
+
+		code, _, _ = execute("-addr", addr, "call", "GreeterService.Greet", "{")
+		assert.Equal(t, exitUsage, code)
+
+		code, _, _ = execute("-serializer", "xml", "list")
+		assert.Equal(t, exitUsage, code)
+	})
+}
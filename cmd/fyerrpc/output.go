@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fyerfyer/fyer-rpc/rpc"
+)
+
+// printService 输出服务及其方法签名，schema为true时同时输出请求和响应的结构
+func printService(w io.Writer, service *rpc.ServiceSchema, schema bool) {
+	fmt.Fprintf(w, "%s %s", service.Name, service.Version)
+	if service.Description != "" {
+		fmt.Fprintf(w, "  # %s", service.Description)
+	}
+	fmt.Fprintln(w)
+
+	for _, method := range service.Methods {
+		fmt.Fprintf(w, "  %s\n", signature(method))
+		if !schema {
+			continue
+		}
+		if method.Request != nil {
+			fmt.Fprintf(w, "    request %s\n", formatType(method.Request, "    "))
+		}
+		if method.Response != nil {
+			fmt.Fprintf(w, "    response %s\n", formatType(method.Response, "    "))
+		}
+	}
+}
+
+// signature 方法签名，如 Greet(Request) returns (Response)
+func signature(method *rpc.MethodSchema) string {
+	switch method.Kind {
+	case rpc.MethodKindServerStream:
+		return fmt.Sprintf("%s(%s) returns (stream)", method.Name, typeName(method.Request))
+	case rpc.MethodKindStream:
+		return fmt.Sprintf("%s(stream) returns (stream)", method.Name)
+	}
+	if method.Response == nil {
+		return fmt.Sprintf("%s(%s)", method.Name, typeName(method.Request))
+	}
+	return fmt.Sprintf("%s(%s) returns (%s)", method.Name, typeName(method.Request), typeName(method.Response))
+}
+
+// typeName 类型的简短名称，Go类型去掉包路径，protobuf消息使用完整名称
+func typeName(t *rpc.TypeSchema) string {
+	if t.ProtoName != "" {
+		return t.ProtoName
+	}
+	switch t.Kind {
+	case "slice":
+		if t.Elem != nil {
+			return "[]" + typeName(t.Elem)
+		}
+	case "map":
+		if t.Key != nil && t.Elem != nil {
+			return fmt.Sprintf("map[%s]%s", typeName(t.Key), typeName(t.Elem))
+		}
+	}
+	return t.Name[strings.LastIndex(t.Name, "/")+1:]
+}
+
+// formatType 展开结构体字段，嵌套结构按缩进逐层输出
+func formatType(t *rpc.TypeSchema, indent string) string {
+	switch {
+	case t.Kind == "slice" && t.Elem != nil:
+		return "[]" + formatType(t.Elem, indent)
+	case t.Kind == "map" && t.Key != nil && t.Elem != nil:
+		return fmt.Sprintf("map[%s]%s", typeName(t.Key), formatType(t.Elem, indent))
+	case len(t.Fields) == 0:
+		return typeName(t)
+	}
+
+	var b strings.Builder
+	b.WriteString(typeName(t))
+	b.WriteString(" {\n")
+	for _, field := range t.Fields {
+		fmt.Fprintf(&b, "%s  %s %s\n", indent, field.Name, formatType(field.Type, indent+"  "))
+	}
+	b.WriteString(indent)
+	b.WriteString("}")
+	return b.String()
+}
+
+// printError 输出错误，RPC错误输出错误码、信息和详情
+func printError(w io.Writer, err error) {
+	var rpcErr *rpc.RPCError
+	if !errors.As(err, &rpcErr) {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(w, "Error: %s (code %d)\n", rpc.CodeText(rpcErr.Code), rpcErr.Code)
+	fmt.Fprintf(w, "Message: %s\n", err)
+	for _, detail := range rpcErr.Details {
+		// JSON详情原样输出，Protobuf详情以base64输出
+		value := string(detail.Value)
+		if !json.Valid(detail.Value) {
+			value = base64.StdEncoding.EncodeToString(detail.Value)
+		}
+		fmt.Fprintf(w, "Detail: %s %s\n", detail.Type, value)
+	}
+}
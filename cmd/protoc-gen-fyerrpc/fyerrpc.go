@@ -0,0 +1,320 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+const (
+	contextPackage  = protogen.GoImportPath("context")
+	ioPackage       = protogen.GoImportPath("io")
+	rpcPackage      = protogen.GoImportPath("github.com/fyerfyer/fyer-rpc/rpc")
+	protocolPackage = protogen.GoImportPath("github.com/fyerfyer/fyer-rpc/protocol")
+)
+
+// generateFile 为包含service定义的文件生成 xxx_fyerrpc.pb.go，没有service时不生成文件
+func generateFile(gen *protogen.Plugin, file *protogen.File) *protogen.GeneratedFile {
+	if len(file.Services) == 0 {
+		return nil
+	}
+
+	g := gen.NewGeneratedFile(file.GeneratedFilenamePrefix+"_fyerrpc.pb.go", file.GoImportPath)
+	g.P("// Code generated by protoc-gen-fyerrpc. DO NOT EDIT.")
+	g.P("// versions:")
+	g.P("// \tprotoc-gen-fyerrpc ", version)
+	g.P("// source: ", file.Desc.Path())
+	g.P()
+	g.P("package ", file.GoPackageName)
+	g.P()
+
+	for _, service := range file.Services {
+		generateService(g, service)
+	}
+	return g
+}
+
+func generateService(g *protogen.GeneratedFile, service *protogen.Service) {
+	serviceName := service.GoName + "_ServiceName"
+	g.P("// ", serviceName, " ", service.GoName, "注册和调用时使用的服务名")
+	g.P("const ", serviceName, " = ", fmt.Sprintf("%q", service.Desc.FullName()))
+	g.P()
+
+	generateClient(g, service, serviceName)
+	generateServer(g, service, serviceName)
+}
+
+// generateClient 生成客户端接口、基于*rpc.Client的实现以及流式方法的类型化流
+func generateClient(g *protogen.GeneratedFile, service *protogen.Service, serviceName string) {
+	clientName := service.GoName + "Client"
+	clientImpl := unexport(clientName)
+
+	g.P("// ", clientName, " ", service.GoName, "服务的客户端")
+	g.P("type ", clientName, " interface {")
+	for _, method := range service.Methods {
+		g.P(method.Comments.Leading, clientSignature(g, method))
+	}
+	g.P("}")
+	g.P()
+
+	g.P("type ", clientImpl, " struct {")
+	g.P("cc *", g.QualifiedGoIdent(rpcPackage.Ident("Client")))
+	g.P("}")
+	g.P()
+
+	g.P("// New", clientName, " 基于已建立的连接创建", service.GoName, "客户端")
+	g.P("// 调用默认使用Protobuf序列化，可以通过rpc.WithCallSerialization覆盖")
+	g.P("func New", clientName, "(cc *", g.QualifiedGoIdent(rpcPackage.Ident("Client")), ") ", clientName, " {")
+	g.P("return &", clientImpl, "{cc: cc}")
+	g.P("}")
+	g.P()
+
+	for _, method := range service.Methods {
+		generateClientMethod(g, service, method, clientImpl, serviceName)
+	}
+}
+
+func clientSignature(g *protogen.GeneratedFile, method *protogen.Method) string {
+	ctx := g.QualifiedGoIdent(contextPackage.Ident("Context"))
+	opts := "opts ..." + g.QualifiedGoIdent(rpcPackage.Ident("CallOption"))
+	switch {
+	case isUnary(method):
+		return fmt.Sprintf("%s(ctx %s, in *%s, %s) (*%s, error)", method.GoName, ctx,
+			g.QualifiedGoIdent(method.Input.GoIdent), opts, g.QualifiedGoIdent(method.Output.GoIdent))
+	case method.Desc.IsStreamingClient():
+		return fmt.Sprintf("%s(ctx %s, %s) (%s, error)", method.GoName, ctx, opts, streamName(method, "Client"))
+	default:
+		return fmt.Sprintf("%s(ctx %s, in *%s, %s) (%s, error)", method.GoName, ctx,
+			g.QualifiedGoIdent(method.Input.GoIdent), opts, streamName(method, "Client"))
+	}
+}
+
+func generateClientMethod(g *protogen.GeneratedFile, service *protogen.Service, method *protogen.Method, clientImpl, serviceName string) {
+	prependOpts := fmt.Sprintf("opts = append([]%s{%s(%s)}, opts...)",
+		g.QualifiedGoIdent(rpcPackage.Ident("CallOption")),
+		g.QualifiedGoIdent(rpcPackage.Ident("WithCallSerialization")),
+		g.QualifiedGoIdent(protocolPackage.Ident("SerializationTypeProtobuf")))
+	input := g.QualifiedGoIdent(method.Input.GoIdent)
+	output := g.QualifiedGoIdent(method.Output.GoIdent)
+
+	g.P("func (c *", clientImpl, ") ", clientSignature(g, method), " {")
+	g.P(prependOpts)
+	if isUnary(method) {
+		g.P("out := new(", output, ")")
+		g.P("if err := c.cc.Invoke(ctx, ", serviceName, ", ", fmt.Sprintf("%q", method.GoName), ", in, out, opts...); err != nil {")
+		g.P("return nil, err")
+		g.P("}")
+		g.P("return out, nil")
+		g.P("}")
+		g.P()
+		return
+	}
+
+	streamImpl := unexport(service.GoName) + method.GoName + "Client"
+	g.P("stream, err := c.cc.NewStream(ctx, ", serviceName, ", ", fmt.Sprintf("%q", method.GoName), ", opts...)")
+	g.P("if err != nil {")
+	g.P("return nil, err")
+	g.P("}")
+	if !method.Desc.IsStreamingClient() {
+		// 服务端流先发送请求并结束发送
+		g.P("if err := stream.Send(in); err != nil {")
+		g.P("return nil, err")
+		g.P("}")
+		g.P("if err := stream.CloseSend(); err != nil {")
+		g.P("return nil, err")
+		g.P("}")
+	}
+	g.P("return &", streamImpl, "{stream}, nil")
+	g.P("}")
+	g.P()
+
+	// 类型化的客户端流
+	ctx := g.QualifiedGoIdent(contextPackage.Ident("Context"))
+	clientStream := g.QualifiedGoIdent(rpcPackage.Ident("ClientStream"))
+	streamType := streamName(method, "Client")
+	g.P("// ", streamType, " ", method.GoName, "的客户端流")
+	g.P("type ", streamType, " interface {")
+	if method.Desc.IsStreamingClient() {
+		g.P("Send(*", input, ") error")
+	}
+	switch {
+	case method.Desc.IsStreamingClient() && !method.Desc.IsStreamingServer():
+		g.P("CloseAndRecv() (*", output, ", error)")
+	case method.Desc.IsStreamingClient():
+		g.P("Recv() (*", output, ", error)")
+		g.P("CloseSend() error")
+	default:
+		g.P("Recv() (*", output, ", error)")
+	}
+	g.P("Context() ", ctx)
+	g.P("}")
+	g.P()
+
+	g.P("type ", streamImpl, " struct {")
+	g.P(clientStream)
+	g.P("}")
+	g.P()
+
+	if method.Desc.IsStreamingClient() {
+		g.P("func (x *", streamImpl, ") Send(m *", input, ") error {")
+		g.P("return x.ClientStream.Send(m)")
+		g.P("}")
+		g.P()
+	}
+	if method.Desc.IsStreamingClient() && !method.Desc.IsStreamingServer() {
+		g.P("func (x *", streamImpl, ") CloseAndRecv() (*", output, ", error) {")
+		g.P("if err := x.ClientStream.CloseSend(); err != nil {")
+		g.P("return nil, err")
+		g.P("}")
+		g.P("m := new(", output, ")")
+		g.P("if err := x.ClientStream.Recv(m); err != nil {")
+		g.P("return nil, err")
+		g.P("}")
+		// 读到流结束为止，服务端在响应之后返回的错误状态不会被忽略
+		g.P("for {")
+		g.P("err := x.ClientStream.Recv(new(", output, "))")
+		g.P("if err == ", g.QualifiedGoIdent(ioPackage.Ident("EOF")), " {")
+		g.P("return m, nil")
+		g.P("}")
+		g.P("if err != nil {")
+		g.P("return nil, err")
+		g.P("}")
+		g.P("}")
+		g.P("}")
+		g.P()
+		return
+	}
+	g.P("func (x *", streamImpl, ") Recv() (*", output, ", error) {")
+	g.P("m := new(", output, ")")
+	g.P("if err := x.ClientStream.Recv(m); err != nil {")
+	g.P("return nil, err")
+	g.P("}")
+	g.P("return m, nil")
+	g.P("}")
+	g.P()
+}
+
+// generateServer 生成服务端接口、适配器、流式方法的类型化流和注册函数
+func generateServer(g *protogen.GeneratedFile, service *protogen.Service, serviceName string) {
+	serverName := service.GoName + "Server"
+	handler := unexport(service.GoName) + "Handler"
+	rpcServer := g.QualifiedGoIdent(rpcPackage.Ident("Server"))
+	serverStream := g.QualifiedGoIdent(rpcPackage.Ident("ServerStream"))
+
+	g.P("// ", serverName, " ", service.GoName, "服务端需要实现的接口")
+	g.P("type ", serverName, " interface {")
+	for _, method := range service.Methods {
+		g.P(method.Comments.Leading, serverSignature(g, method))
+	}
+	g.P("}")
+	g.P()
+
+	g.P("// Register", serverName, " 将", service.GoName, "的实现注册到服务器，只暴露service中定义的方法")
+	g.P("func Register", serverName, "(s *", rpcServer, ", srv ", serverName, ", opts ...", g.QualifiedGoIdent(rpcPackage.Ident("RegisterOption")), ") error {")
+	g.P("return s.RegisterName(", serviceName, ", \"\", &", handler, "{srv: srv}, opts...)")
+	g.P("}")
+	g.P()
+
+	g.P("// ", handler, " 将", serverName, "适配为rpc.Server可以注册的服务")
+	g.P("type ", handler, " struct {")
+	g.P("srv ", serverName)
+	g.P("}")
+	g.P()
+
+	ctx := g.QualifiedGoIdent(contextPackage.Ident("Context"))
+	for _, method := range service.Methods {
+		input := g.QualifiedGoIdent(method.Input.GoIdent)
+		output := g.QualifiedGoIdent(method.Output.GoIdent)
+		streamImpl := unexport(service.GoName) + method.GoName + "Server"
+
+		switch {
+		case isUnary(method):
+			g.P("func (h *", handler, ") ", method.GoName, "(ctx ", ctx, ", in *", input, ") (*", output, ", error) {")
+			g.P("return h.srv.", method.GoName, "(ctx, in)")
+			g.P("}")
+			g.P()
+			continue
+		case method.Desc.IsStreamingClient():
+			g.P("func (h *", handler, ") ", method.GoName, "(stream ", serverStream, ") error {")
+			g.P("return h.srv.", method.GoName, "(&", streamImpl, "{stream})")
+		default:
+			g.P("func (h *", handler, ") ", method.GoName, "(in *", input, ", stream ", serverStream, ") error {")
+			g.P("return h.srv.", method.GoName, "(in, &", streamImpl, "{stream})")
+		}
+		g.P("}")
+		g.P()
+
+		// 类型化的服务端流
+		streamType := streamName(method, "Server")
+		g.P("// ", streamType, " ", method.GoName, "的服务端流")
+		g.P("type ", streamType, " interface {")
+		switch {
+		case method.Desc.IsStreamingClient() && !method.Desc.IsStreamingServer():
+			g.P("Recv() (*", input, ", error)")
+			g.P("SendAndClose(*", output, ") error")
+		case method.Desc.IsStreamingClient():
+			g.P("Send(*", output, ") error")
+			g.P("Recv() (*", input, ", error)")
+		default:
+			g.P("Send(*", output, ") error")
+		}
+		g.P(serverStream)
+		g.P("}")
+		g.P()
+
+		g.P("type ", streamImpl, " struct {")
+		g.P(serverStream)
+		g.P("}")
+		g.P()
+
+		if method.Desc.IsStreamingServer() {
+			g.P("func (x *", streamImpl, ") Send(m *", output, ") error {")
+			g.P("return x.ServerStream.SendMsg(m)")
+			g.P("}")
+			g.P()
+		} else {
+			// 客户端流方法返回后服务端结束流
+			g.P("func (x *", streamImpl, ") SendAndClose(m *", output, ") error {")
+			g.P("return x.ServerStream.SendMsg(m)")
+			g.P("}")
+			g.P()
+		}
+		if method.Desc.IsStreamingClient() {
+			g.P("func (x *", streamImpl, ") Recv() (*", input, ", error) {")
+			g.P("m := new(", input, ")")
+			g.P("if err := x.ServerStream.RecvMsg(m); err != nil {")
+			g.P("return nil, err")
+			g.P("}")
+			g.P("return m, nil")
+			g.P("}")
+			g.P()
+		}
+	}
+}
+
+func serverSignature(g *protogen.GeneratedFile, method *protogen.Method) string {
+	input := g.QualifiedGoIdent(method.Input.GoIdent)
+	switch {
+	case isUnary(method):
+		return fmt.Sprintf("%s(%s, *%s) (*%s, error)", method.GoName,
+			g.QualifiedGoIdent(contextPackage.Ident("Context")), input, g.QualifiedGoIdent(method.Output.GoIdent))
+	case method.Desc.IsStreamingClient():
+		return fmt.Sprintf("%s(%s) error", method.GoName, streamName(method, "Server"))
+	default:
+		return fmt.Sprintf("%s(*%s, %s) error", method.GoName, input, streamName(method, "Server"))
+	}
+}
+
+func isUnary(method *protogen.Method) bool {
+	return !method.Desc.IsStreamingClient() && !method.Desc.IsStreamingServer()
+}
+
+// streamName 流式方法的类型化流名称，如 GreetService_ChatClient
+func streamName(method *protogen.Method, side string) string {
+	return method.Parent.GoName + "_" + method.GoName + side
+}
+
+func unexport(s string) string {
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/cmd/protoc-gen-fyerrpc/testdata/chat"
+	"github.com/fyerfyer/fyer-rpc/example/helloworld"
+	"github.com/fyerfyer/fyer-rpc/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var update = flag.Bool("update", false, "regenerate the golden *_fyerrpc.pb.go files")
+
+// generate 以protoc的方式调用插件，返回生成的文件名和内容
+func generate(t *testing.T, files ...*descriptorpb.FileDescriptorProto) map[string]string {
+	req := &pluginpb.CodeGeneratorRequest{
+		Parameter: proto.String("paths=source_relative"),
+		ProtoFile: files,
+	}
+	for _, f := range files {
+		req.FileToGenerate = append(req.FileToGenerate, f.GetName())
+	}
+
+	gen, err := protogen.Options{}.New(req)
+	require.NoError(t, err)
+	for _, f := range gen.Files {
+		if f.Generate {
+			generateFile(gen, f)
+		}
+	}
+	resp := gen.Response()
+	require.Empty(t, resp.GetError())
+
+	out := make(map[string]string)
+	for _, f := range resp.File {
+		out[f.GetName()] = f.GetContent()
+	}
+	return out
+}
+
+// checkGolden 比较插件为file生成的代码与已提交的文件，-update时重新生成该文件
+func checkGolden(t *testing.T, file protoreflect.FileDescriptor, golden string) {
+	out := generate(t, protodesc.ToFileDescriptorProto(file))
+	name := filepath.Base(golden)
+	require.Contains(t, out, name)
+	content := out[name]
+
+	if *update {
+		require.NoError(t, os.WriteFile(golden, []byte(content), 0644))
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), content, "generated code is stale, run go test ./cmd/protoc-gen-fyerrpc -update")
+}
+
+func TestGenerateHelloworld(t *testing.T) {
+	checkGolden(t, helloworld.File_hello_proto, "../../example/helloworld/hello_fyerrpc.pb.go")
+}
+
+func TestGenerateStreams(t *testing.T) {
+	checkGolden(t, chat.File_chat_proto, "testdata/chat/chat_fyerrpc.pb.go")
+
+	// 没有service的文件不生成代码
+	file := protodesc.ToFileDescriptorProto(chat.File_chat_proto)
+	file.Service = nil
+	assert.Empty(t, generate(t, file))
+}
+
+// greetServer 使用生成的服务端接口实现问候服务
+type greetServer struct {
+	*helloworld.GreetServiceImpl
+}
+
+func TestGeneratedStubs(t *testing.T) {
+	server := rpc.NewServer()
+	require.NoError(t, helloworld.RegisterGreetServiceServer(server, &greetServer{helloworld.NewGreetService()}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	defer server.Shutdown(context.Background())
+
+	conn, err := rpc.NewClient(listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := helloworld.NewGreetServiceClient(conn)
+
+	resp, err := client.SayHello(context.Background(), &helloworld.HelloRequest{Name: "fyer"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, fyer!", resp.Message)
+
+	stats, err := client.GetGreetStats(context.Background(), &helloworld.StatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.TotalGreets)
+
+	// 只暴露service中定义的方法
+	desc := server.Services()[0]
+	assert.Equal(t, helloworld.GreetService_ServiceName, desc.ServiceName)
+	assert.Len(t, desc.Methods, 2)
+}
+
+// chatServer 使用生成的流式接口实现的聊天服务
+type chatServer struct{}
+
+func (s *chatServer) Subscribe(in *chat.Message, stream chat.Chat_SubscribeServer) error {
+	for i := 0; i < 3; i++ {
+		if err := stream.Send(&chat.Message{Text: fmt.Sprintf("%s-%d", in.Text, i)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Upload 拼接收到的消息，收到"reject"时在响应之后返回错误
+func (s *chatServer) Upload(stream chat.Chat_UploadServer) error {
+	var text string
+	reject := false
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		text += m.Text
+		reject = reject || m.Text == "reject"
+	}
+	if err := stream.SendAndClose(&chat.Message{Text: text}); err != nil {
+		return err
+	}
+	if reject {
+		return rpc.NewRPCError(rpc.ErrCodeInvalidParam, "upload rejected")
+	}
+	return nil
+}
+
+func (s *chatServer) Talk(stream chat.Chat_TalkServer) error {
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&chat.Message{Text: "echo " + m.Text}); err != nil {
+			return err
+		}
+	}
+}
+
+func TestGeneratedStreams(t *testing.T) {
+	server := rpc.NewServer()
+	require.NoError(t, chat.RegisterChatServer(server, &chatServer{}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	defer server.Shutdown(context.Background())
+
+	conn, err := rpc.NewClient(listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := chat.NewChatClient(conn)
+	ctx := context.Background()
+
+	t.Run("server streaming", func(t *testing.T) {
+		stream, err := client.Subscribe(ctx, &chat.Message{Text: "news"})
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			m, err := stream.Recv()
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("news-%d", i), m.Text)
+		}
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("client streaming", func(t *testing.T) {
+		upload := func(texts ...string) (*chat.Message, error) {
+			stream, err := client.Upload(ctx)
+			require.NoError(t, err)
+			for _, text := range texts {
+				require.NoError(t, stream.Send(&chat.Message{Text: text}))
+			}
+			return stream.CloseAndRecv()
+		}
+
+		m, err := upload("a", "b", "c")
+		require.NoError(t, err)
+		assert.Equal(t, "abc", m.Text)
+
+		// 服务端在响应之后返回的错误状态
+		_, err = upload("a", "reject")
+		assert.ErrorIs(t, err, rpc.ErrInvalidParam)
+		assert.Contains(t, err.Error(), "upload rejected")
+
+		// CloseAndRecv读到流结束，流不会残留协程
+		before := runtime.NumGoroutine()
+		for i := 0; i < 50; i++ {
+			_, err := upload("x")
+			require.NoError(t, err)
+		}
+		assert.Eventually(t, func() bool {
+			return runtime.NumGoroutine() <= before+5
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("bidirectional streaming", func(t *testing.T) {
+		stream, err := client.Talk(ctx)
+		require.NoError(t, err)
+		for _, text := range []string{"hi", "bye"} {
+			require.NoError(t, stream.Send(&chat.Message{Text: text}))
+			m, err := stream.Recv()
+			require.NoError(t, err)
+			assert.Equal(t, "echo "+text, m.Text)
+		}
+		require.NoError(t, stream.CloseSend())
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+}
@@ -0,0 +1,41 @@
+// protoc-gen-fyerrpc 根据.proto文件中的service定义生成fyerrpc的类型化客户端和服务端代码
+//
+// 安装后与protoc-gen-go一起使用:
+//
+//	protoc --go_out=. --fyerrpc_out=. hello.proto
+//
+// 对每个service生成:
+//   - XxxClient 客户端接口及基于*rpc.Client的实现，调用默认使用Protobuf序列化
+//   - XxxServer 服务端接口
+//   - RegisterXxxServer 将服务端实现注册到*rpc.Server
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const version = "1.0.0"
+
+func main() {
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	flag.Parse()
+	if *showVersion {
+		fmt.Printf("protoc-gen-fyerrpc %s\n", version)
+		os.Exit(0)
+	}
+
+	protogen.Options{}.Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		for _, f := range gen.Files {
+			if f.Generate {
+				generateFile(gen, f)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,231 @@
+// Code generated by protoc-gen-fyerrpc. DO NOT EDIT.
+// versions:
+// 	protoc-gen-fyerrpc 1.0.0
+// source: chat.proto
+
+package chat
+
+import (
+	context "context"
+	protocol "github.com/fyerfyer/fyer-rpc/protocol"
+	rpc "github.com/fyerfyer/fyer-rpc/rpc"
+	io "io"
+)
+
+// Chat_ServiceName Chat注册和调用时使用的服务名
+const Chat_ServiceName = "chat.Chat"
+
+// ChatClient Chat服务的客户端
+type ChatClient interface {
+	Subscribe(ctx context.Context, in *Message, opts ...rpc.CallOption) (Chat_SubscribeClient, error)
+	Upload(ctx context.Context, opts ...rpc.CallOption) (Chat_UploadClient, error)
+	Talk(ctx context.Context, opts ...rpc.CallOption) (Chat_TalkClient, error)
+}
+
+type chatClient struct {
+	cc *rpc.Client
+}
+
+// NewChatClient 基于已建立的连接创建Chat客户端
+// 调用默认使用Protobuf序列化，可以通过rpc.WithCallSerialization覆盖
+func NewChatClient(cc *rpc.Client) ChatClient {
+	return &chatClient{cc: cc}
+}
+
+func (c *chatClient) Subscribe(ctx context.Context, in *Message, opts ...rpc.CallOption) (Chat_SubscribeClient, error) {
+	opts = append([]rpc.CallOption{rpc.WithCallSerialization(protocol.SerializationTypeProtobuf)}, opts...)
+	stream, err := c.cc.NewStream(ctx, Chat_ServiceName, "Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(in); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &chatSubscribeClient{stream}, nil
+}
+
+// Chat_SubscribeClient Subscribe的客户端流
+type Chat_SubscribeClient interface {
+	Recv() (*Message, error)
+	Context() context.Context
+}
+
+type chatSubscribeClient struct {
+	rpc.ClientStream
+}
+
+func (x *chatSubscribeClient) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ClientStream.Recv(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatClient) Upload(ctx context.Context, opts ...rpc.CallOption) (Chat_UploadClient, error) {
+	opts = append([]rpc.CallOption{rpc.WithCallSerialization(protocol.SerializationTypeProtobuf)}, opts...)
+	stream, err := c.cc.NewStream(ctx, Chat_ServiceName, "Upload", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &chatUploadClient{stream}, nil
+}
+
+// Chat_UploadClient Upload的客户端流
+type Chat_UploadClient interface {
+	Send(*Message) error
+	CloseAndRecv() (*Message, error)
+	Context() context.Context
+}
+
+type chatUploadClient struct {
+	rpc.ClientStream
+}
+
+func (x *chatUploadClient) Send(m *Message) error {
+	return x.ClientStream.Send(m)
+}
+
+func (x *chatUploadClient) CloseAndRecv() (*Message, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Message)
+	if err := x.ClientStream.Recv(m); err != nil {
+		return nil, err
+	}
+	for {
+		err := x.ClientStream.Recv(new(Message))
+		if err == io.EOF {
+			return m, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *chatClient) Talk(ctx context.Context, opts ...rpc.CallOption) (Chat_TalkClient, error) {
+	opts = append([]rpc.CallOption{rpc.WithCallSerialization(protocol.SerializationTypeProtobuf)}, opts...)
+	stream, err := c.cc.NewStream(ctx, Chat_ServiceName, "Talk", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &chatTalkClient{stream}, nil
+}
+
+// Chat_TalkClient Talk的客户端流
+type Chat_TalkClient interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	CloseSend() error
+	Context() context.Context
+}
+
+type chatTalkClient struct {
+	rpc.ClientStream
+}
+
+func (x *chatTalkClient) Send(m *Message) error {
+	return x.ClientStream.Send(m)
+}
+
+func (x *chatTalkClient) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ClientStream.Recv(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ChatServer Chat服务端需要实现的接口
+type ChatServer interface {
+	Subscribe(*Message, Chat_SubscribeServer) error
+	Upload(Chat_UploadServer) error
+	Talk(Chat_TalkServer) error
+}
+
+// RegisterChatServer 将Chat的实现注册到服务器，只暴露service中定义的方法
+func RegisterChatServer(s *rpc.Server, srv ChatServer, opts ...rpc.RegisterOption) error {
+	return s.RegisterName(Chat_ServiceName, "", &chatHandler{srv: srv}, opts...)
+}
+
+// chatHandler 将ChatServer适配为rpc.Server可以注册的服务
+type chatHandler struct {
+	srv ChatServer
+}
+
+func (h *chatHandler) Subscribe(in *Message, stream rpc.ServerStream) error {
+	return h.srv.Subscribe(in, &chatSubscribeServer{stream})
+}
+
+// Chat_SubscribeServer Subscribe的服务端流
+type Chat_SubscribeServer interface {
+	Send(*Message) error
+	rpc.ServerStream
+}
+
+type chatSubscribeServer struct {
+	rpc.ServerStream
+}
+
+func (x *chatSubscribeServer) Send(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (h *chatHandler) Upload(stream rpc.ServerStream) error {
+	return h.srv.Upload(&chatUploadServer{stream})
+}
+
+// Chat_UploadServer Upload的服务端流
+type Chat_UploadServer interface {
+	Recv() (*Message, error)
+	SendAndClose(*Message) error
+	rpc.ServerStream
+}
+
+type chatUploadServer struct {
+	rpc.ServerStream
+}
+
+func (x *chatUploadServer) SendAndClose(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *chatUploadServer) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (h *chatHandler) Talk(stream rpc.ServerStream) error {
+	return h.srv.Talk(&chatTalkServer{stream})
+}
+
+// Chat_TalkServer Talk的服务端流
+type Chat_TalkServer interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	rpc.ServerStream
+}
+
+type chatTalkServer struct {
+	rpc.ServerStream
+}
+
+func (x *chatTalkServer) Send(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *chatTalkServer) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -20,6 +20,8 @@ type ClientConfig struct {
 	KeepAliveCount  int           // 保活探测次数
 	KeepAliveIdle   time.Duration // 连接空闲多久开始保活探测
 	ConnectionLimit int           // 单个地址最大连接数
+	MaxHeaderBytes  int           // 接受的最大响应元数据大小(字节)
+	MaxResponseSize int           // 接受的最大响应消息体大小(字节)
 
 	// 负载均衡相关配置
 	LoadBalanceType    balancer.BalancerType // 负载均衡类型
@@ -50,6 +52,8 @@ var DefaultClientConfig = &ClientConfig{
 	KeepAliveCount:  3,
 	KeepAliveIdle:   time.Second * 60,
 	ConnectionLimit: 100,
+	MaxHeaderBytes:  1 << 20, // 1MB
+	MaxResponseSize: 4 << 20, // 4MB
 
 	// 负载均衡相关默认配置
 	LoadBalanceType:    balancer.Random,
@@ -149,6 +153,18 @@ func WithConnectionLimit(limit int) ClientOption {
 	}
 }
 
+// WithMessageSizeLimit 设置接受的最大响应元数据和消息体大小
+func WithMessageSizeLimit(maxHeaderBytes, maxResponseSize int) ClientOption {
+	return func(c *ClientConfig) {
+		if maxHeaderBytes > 0 {
+			c.MaxHeaderBytes = maxHeaderBytes
+		}
+		if maxResponseSize > 0 {
+			c.MaxResponseSize = maxResponseSize
+		}
+	}
+}
+
 // NewClientConfig 创建客户端配置
 func NewClientConfig(options ...ClientOption) *ClientConfig {
 	// 创建默认配置的副本
@@ -27,6 +27,10 @@ const (
 	CompressNone CompressType = CompressType(protocol.CompressTypeNone)
 	// CompressGzip Gzip压缩
 	CompressGzip CompressType = CompressType(protocol.CompressTypeGzip)
+	// CompressDeflate Deflate压缩
+	CompressDeflate CompressType = CompressType(protocol.CompressTypeDeflate)
+	// CompressZlib Zlib压缩
+	CompressZlib CompressType = CompressType(protocol.CompressTypeZlib)
 )
 
 // CommonConfig 通用配置选项
@@ -39,6 +43,7 @@ type CommonConfig struct {
 	// 协议配置
 	SerializationType SerializationType // 序列化类型
 	CompressType      CompressType      // 压缩类型
+	CompressMinSize   int               // 启用压缩的最小消息体大小(字节)
 	ProtocolVersion   uint8             // 协议版本
 
 	// 超时配置
@@ -72,6 +77,7 @@ var DefaultCommonConfig = &CommonConfig{
 	// 协议配置
 	SerializationType: SerializationJSON,
 	CompressType:      CompressNone,
+	CompressMinSize:   1024,
 	ProtocolVersion:   1,
 
 	// 超时配置
@@ -133,6 +139,15 @@ func WithCompression(compType CompressType) CommonOption {
 	}
 }
 
+// WithCompressMinSize 设置启用压缩的最小消息体大小
+func WithCompressMinSize(size int) CommonOption {
+	return func(c *CommonConfig) {
+		if size >= 0 {
+			c.CompressMinSize = size
+		}
+	}
+}
+
 // WithTimeouts 设置超时时间
 func WithTimeouts(dialTimeout, requestTimeout time.Duration) CommonOption {
 	return func(c *CommonConfig) {
@@ -16,6 +16,8 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration // 写入超时
 	ShutdownTimeout time.Duration // 优雅关闭超时
 	MaxHeaderBytes  int           // 最大请求头大小
+	KeepAliveTime   time.Duration // 连接空闲多久发送心跳，为0时不检测客户端存活
+	KeepAliveCount  int           // 连续多少次心跳未响应时关闭连接
 
 	// 注册中心配置
 	RegisterTTL      int64             // 服务注册租约时间（秒）
@@ -27,13 +29,14 @@ type ServerConfig struct {
 	Metadata         map[string]string // 服务元数据
 
 	// 处理器配置
-	WorkerPoolSize   int           // 工作线程池大小
-	MaxRequestSize   int           // 最大请求大小(字节)
-	MaxConcurrent    int           // 最大并发请求数
-	SlowRequestTime  time.Duration // 慢请求阈值
-	EnableAccessLog  bool          // 是否启用访问日志
-	EnableMetricsLog bool          // 是否启用指标日志
-	MetricsLogPeriod time.Duration // 指标日志周期
+	WorkerPoolSize       int           // 工作线程池大小
+	MaxRequestSize       int           // 最大请求大小(字节)
+	MaxConcurrent        int           // 最大并发请求数
+	MaxConcurrentStreams int           // 每个连接的最大并发流数，为0时不限制
+	SlowRequestTime      time.Duration // 慢请求阈值
+	EnableAccessLog      bool          // 是否启用访问日志
+	EnableMetricsLog     bool          // 是否启用指标日志
+	MetricsLogPeriod     time.Duration // 指标日志周期
 }
 
 // DefaultServerConfig 服务器默认配置
@@ -47,6 +50,8 @@ var DefaultServerConfig = &ServerConfig{
 	WriteTimeout:    time.Second * 30,
 	ShutdownTimeout: time.Second * 10,
 	MaxHeaderBytes:  1 << 20, // 1MB
+	KeepAliveTime:   time.Second * 30,
+	KeepAliveCount:  3,
 
 	// 注册中心默认配置
 	RegisterTTL:      30,
@@ -58,13 +63,14 @@ var DefaultServerConfig = &ServerConfig{
 	Metadata:         make(map[string]string),
 
 	// 处理器默认配置
-	WorkerPoolSize:   runtime.NumCPU() * 2,
-	MaxRequestSize:   4 << 20, // 4MB
-	MaxConcurrent:    100,
-	SlowRequestTime:  time.Second * 1,
-	EnableAccessLog:  true,
-	EnableMetricsLog: false,
-	MetricsLogPeriod: time.Minute,
+	WorkerPoolSize:       runtime.NumCPU() * 2,
+	MaxRequestSize:       4 << 20, // 4MB
+	MaxConcurrent:        100,
+	MaxConcurrentStreams: 100,
+	SlowRequestTime:      time.Second * 1,
+	EnableAccessLog:      true,
+	EnableMetricsLog:     false,
+	MetricsLogPeriod:     time.Minute,
 }
 
 // ServerOption 服务器配置选项函数类型
@@ -101,6 +107,18 @@ func WithShutdownTimeout(timeout time.Duration) ServerOption {
 	}
 }
 
+// WithServerKeepAlive 设置检测客户端存活的心跳配置，keepAliveTime为0时关闭检测
+func WithServerKeepAlive(keepAliveTime time.Duration, keepAliveCount int) ServerOption {
+	return func(c *ServerConfig) {
+		if keepAliveTime >= 0 {
+			c.KeepAliveTime = keepAliveTime
+		}
+		if keepAliveCount > 0 {
+			c.KeepAliveCount = keepAliveCount
+		}
+	}
+}
+
 // WithServiceInfo 设置服务信息
 func WithServiceInfo(name, version string, weight int) ServerOption {
 	return func(c *ServerConfig) {
@@ -146,6 +164,15 @@ func WithConcurrencyLimit(maxConcurrent, maxRequestSize int) ServerOption {
 	}
 }
 
+// WithMaxConcurrentStreams 设置每个连接的最大并发流数，为0时不限制
+func WithMaxConcurrentStreams(maxStreams int) ServerOption {
+	return func(c *ServerConfig) {
+		if maxStreams >= 0 {
+			c.MaxConcurrentStreams = maxStreams
+		}
+	}
+}
+
 // WithRegistryConfig 设置注册中心配置
 func WithRegistryConfig(enable bool, ttl int64, interval time.Duration) ServerOption {
 	return func(c *ServerConfig) {
@@ -0,0 +1,73 @@
+// Code generated by protoc-gen-fyerrpc. DO NOT EDIT.
+// versions:
+// 	protoc-gen-fyerrpc 1.0.0
+// source: hello.proto
+
+package helloworld
+
+import (
+	context "context"
+	protocol "github.com/fyerfyer/fyer-rpc/protocol"
+	rpc "github.com/fyerfyer/fyer-rpc/rpc"
+)
+
+// GreetService_ServiceName GreetService注册和调用时使用的服务名
+const GreetService_ServiceName = "GreetService"
+
+// GreetServiceClient GreetService服务的客户端
+type GreetServiceClient interface {
+	SayHello(ctx context.Context, in *HelloRequest, opts ...rpc.CallOption) (*HelloResponse, error)
+	GetGreetStats(ctx context.Context, in *StatsRequest, opts ...rpc.CallOption) (*StatsResponse, error)
+}
+
+type greetServiceClient struct {
+	cc *rpc.Client
+}
+
+// NewGreetServiceClient 基于已建立的连接创建GreetService客户端
+// 调用默认使用Protobuf序列化，可以通过rpc.WithCallSerialization覆盖
+func NewGreetServiceClient(cc *rpc.Client) GreetServiceClient {
+	return &greetServiceClient{cc: cc}
+}
+
+func (c *greetServiceClient) SayHello(ctx context.Context, in *HelloRequest, opts ...rpc.CallOption) (*HelloResponse, error) {
+	opts = append([]rpc.CallOption{rpc.WithCallSerialization(protocol.SerializationTypeProtobuf)}, opts...)
+	out := new(HelloResponse)
+	if err := c.cc.Invoke(ctx, GreetService_ServiceName, "SayHello", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greetServiceClient) GetGreetStats(ctx context.Context, in *StatsRequest, opts ...rpc.CallOption) (*StatsResponse, error) {
+	opts = append([]rpc.CallOption{rpc.WithCallSerialization(protocol.SerializationTypeProtobuf)}, opts...)
+	out := new(StatsResponse)
+	if err := c.cc.Invoke(ctx, GreetService_ServiceName, "GetGreetStats", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GreetServiceServer GreetService服务端需要实现的接口
+type GreetServiceServer interface {
+	SayHello(context.Context, *HelloRequest) (*HelloResponse, error)
+	GetGreetStats(context.Context, *StatsRequest) (*StatsResponse, error)
+}
+
+// RegisterGreetServiceServer 将GreetService的实现注册到服务器，只暴露service中定义的方法
+func RegisterGreetServiceServer(s *rpc.Server, srv GreetServiceServer, opts ...rpc.RegisterOption) error {
+	return s.RegisterName(GreetService_ServiceName, "", &greetServiceHandler{srv: srv}, opts...)
+}
+
+// greetServiceHandler 将GreetServiceServer适配为rpc.Server可以注册的服务
+type greetServiceHandler struct {
+	srv GreetServiceServer
+}
+
+func (h *greetServiceHandler) SayHello(ctx context.Context, in *HelloRequest) (*HelloResponse, error) {
+	return h.srv.SayHello(ctx, in)
+}
+
+func (h *greetServiceHandler) GetGreetStats(ctx context.Context, in *StatsRequest) (*StatsResponse, error) {
+	return h.srv.GetGreetStats(ctx, in)
+}
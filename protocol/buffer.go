@@ -0,0 +1,56 @@
+package protocol
+
+import (
+	"bytes"
+	"sync"
+)
+
+// 消息体缓冲区按容量分级复用，超过最大级别的缓冲区直接分配，不放回缓冲池
+var bufferSizes = []int{1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20}
+
+var bufferPools = make([]sync.Pool, len(bufferSizes))
+
+// GetBuffer 从缓冲池获取长度为size的缓冲区，内容未初始化
+func GetBuffer(size int) []byte {
+	for i, capacity := range bufferSizes {
+		if size <= capacity {
+			if buf, ok := bufferPools[i].Get().(*[]byte); ok {
+				return (*buf)[:size]
+			}
+			return make([]byte, size, capacity)
+		}
+	}
+	return make([]byte, size)
+}
+
+// PutBuffer 将GetBuffer获取的缓冲区归还给缓冲池，归还后不能再使用该缓冲区
+func PutBuffer(buf []byte) {
+	for i, capacity := range bufferSizes {
+		if cap(buf) == capacity {
+			buf = buf[:0]
+			bufferPools[i].Put(&buf)
+			return
+		}
+	}
+}
+
+// maxPooledEncodeBuffer 编码缓冲区超过该容量时不再复用，避免缓冲池长期占用大块内存
+const maxPooledEncodeBuffer = 1 << 20
+
+var encodeBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+func getEncodeBuffer() *bytes.Buffer {
+	return encodeBufferPool.Get().(*bytes.Buffer)
+}
+
+func putEncodeBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledEncodeBuffer {
+		return
+	}
+	buf.Reset()
+	encodeBufferPool.Put(buf)
+}
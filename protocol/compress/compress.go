@@ -0,0 +1,87 @@
+package compress
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrInvalidData = errors.New("compressed data is invalid")
+)
+
+// Compressor 定义了压缩和解压缩的接口
+type Compressor interface {
+	// Compress 压缩字节数组
+	Compress(data []byte) ([]byte, error)
+
+	// Decompress 解压缩字节数组
+	Decompress(data []byte) ([]byte, error)
+
+	// Name 返回压缩器的名称
+	Name() string
+}
+
+// ReaderDecompressor 支持流式解压的压缩器
+// 解码消息时通过它在解压过程中限制解压后的大小，未实现该接口的压缩器先完整解压再检查大小
+type ReaderDecompressor interface {
+	// NewReader 返回读取r解压后数据的Reader
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// readAll 通过流式解压完整读取解压后的数据
+func readAll(d ReaderDecompressor, data []byte) ([]byte, error) {
+	reader, err := d.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// Type 定义了支持的压缩类型
+// 取值与协议头中的CompressType一致，0表示不压缩，不能用于注册
+type Type uint8
+
+const (
+	Gzip Type = iota + 1
+	Deflate
+	Zlib
+)
+
+var (
+	mu          sync.RWMutex
+	compressors = make(map[Type]Compressor)
+)
+
+// RegisterCompressor 注册压缩器，已注册的类型会被覆盖
+// 注册后即可通过协议头中的CompressType选用该压缩器
+func RegisterCompressor(t Type, compressor Compressor) {
+	if t == 0 {
+		panic("compress: type 0 is reserved for uncompressed payloads")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	compressors[t] = compressor
+}
+
+// GetCompressor 获取压缩器
+func GetCompressor(t Type) Compressor {
+	mu.RLock()
+	defer mu.RUnlock()
+	return compressors[t]
+}
+
+// Types 返回已注册的压缩类型，按类型值升序排列
+func Types() []Type {
+	mu.RLock()
+	defer mu.RUnlock()
+	types := make([]Type, 0, len(compressors))
+	for t := range compressors {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
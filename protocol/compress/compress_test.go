@@ -0,0 +1,47 @@
+package compress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressor(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"name":"test","age":20}`), 100)
+
+	tests := []struct {
+		name string
+		typ  Type
+	}{
+		{name: "gzip", typ: Gzip},
+		{name: "deflate", typ: Deflate},
+		{name: "zlib", typ: Zlib},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressor := GetCompressor(tt.typ)
+			require.NotNil(t, compressor)
+			assert.Equal(t, tt.name, compressor.Name())
+
+			compressed, err := compressor.Compress(data)
+			require.NoError(t, err)
+			assert.Less(t, len(compressed), len(data))
+
+			decompressed, err := compressor.Decompress(compressed)
+			require.NoError(t, err)
+			assert.Equal(t, data, decompressed)
+		})
+	}
+
+	t.Run("invalid data", func(t *testing.T) {
+		_, err := GetCompressor(Gzip).Decompress([]byte("not gzip"))
+		assert.Error(t, err)
+	})
+
+	t.Run("compressor not found", func(t *testing.T) {
+		assert.Nil(t, GetCompressor(Type(33)))
+	})
+}
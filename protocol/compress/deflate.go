@@ -0,0 +1,45 @@
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+)
+
+func init() {
+	RegisterCompressor(Deflate, &DeflateCompressor{})
+}
+
+// DeflateCompressor 实现了 Compressor 接口
+type DeflateCompressor struct{}
+
+// Compress 使用 deflate 压缩数据
+func (c *DeflateCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress 解压 deflate 数据
+func (c *DeflateCompressor) Decompress(data []byte) ([]byte, error) {
+	return readAll(c, data)
+}
+
+// NewReader 返回读取 deflate 解压后数据的 Reader
+func (c *DeflateCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+// Name 返回压缩器的名称
+func (c *DeflateCompressor) Name() string {
+	return "deflate"
+}
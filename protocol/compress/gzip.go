@@ -0,0 +1,46 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+func init() {
+	RegisterCompressor(Gzip, &GzipCompressor{})
+}
+
+// GzipCompressor 实现了 Compressor 接口
+type GzipCompressor struct{}
+
+// Compress 使用 gzip 压缩数据
+func (c *GzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress 解压 gzip 数据
+func (c *GzipCompressor) Decompress(data []byte) ([]byte, error) {
+	return readAll(c, data)
+}
+
+// NewReader 返回读取 gzip 解压后数据的 Reader
+func (c *GzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	reader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, ErrInvalidData
+	}
+	return reader, nil
+}
+
+// Name 返回压缩器的名称
+func (c *GzipCompressor) Name() string {
+	return "gzip"
+}
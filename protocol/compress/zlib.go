@@ -0,0 +1,46 @@
+package compress
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+)
+
+func init() {
+	RegisterCompressor(Zlib, &ZlibCompressor{})
+}
+
+// ZlibCompressor 实现了 Compressor 接口
+type ZlibCompressor struct{}
+
+// Compress 使用 zlib 压缩数据
+func (c *ZlibCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := zlib.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress 解压 zlib 数据
+func (c *ZlibCompressor) Decompress(data []byte) ([]byte, error) {
+	return readAll(c, data)
+}
+
+// NewReader 返回读取 zlib 解压后数据的 Reader
+func (c *ZlibCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	reader, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, ErrInvalidData
+	}
+	return reader, nil
+}
+
+// Name 返回压缩器的名称
+func (c *ZlibCompressor) Name() string {
+	return "zlib"
+}
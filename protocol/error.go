@@ -1,9 +1,13 @@
 package protocol
 
+import "fmt"
+
 // 错误定义
 var (
 	ErrInvalidMagic          = NewError("invalid magic number")
 	ErrUnsupportedSerializer = NewError("unsupported serializer type")
+	ErrUnsupportedCompressor = NewError("unsupported compress type")
+	ErrFrameTooLarge         = NewError("frame too large")
 )
 
 // Error 自定义错误类型
@@ -19,3 +23,21 @@ func (e *Error) Error() string {
 func NewError(message string) *Error {
 	return &Error{message: message}
 }
+
+// FrameTooLargeError 消息的元数据或消息体超过大小限制
+// 返回该错误时消息体已被丢弃，连接仍可继续读取下一条消息
+type FrameTooLargeError struct {
+	Header Header // 超限消息的头部，可用于向对端返回错误
+	Field  string // 超限的部分：metadata 或 payload
+	Size   uint32 // 实际大小，解压后超限时为停止解压前读取的字节数
+	Limit  uint32 // 大小限制
+}
+
+func (e *FrameTooLargeError) Error() string {
+	return fmt.Sprintf("frame too large: %s size %d exceeds limit %d", e.Field, e.Size, e.Limit)
+}
+
+// Is 使 errors.Is(err, ErrFrameTooLarge) 成立
+func (e *FrameTooLargeError) Is(target error) bool {
+	return target == ErrFrameTooLarge
+}
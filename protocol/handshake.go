@@ -0,0 +1,132 @@
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/fyerfyer/fyer-rpc/protocol/compress"
+)
+
+// 协议版本
+const (
+	ProtocolVersion    = uint8(1) // 当前实现支持的最高协议版本
+	MinProtocolVersion = uint8(1) // 当前实现支持的最低协议版本
+)
+
+// HandshakeServiceName 握手消息元数据中的服务名
+// 旧版本服务端不识别握手消息，会按普通请求查找该服务并返回服务不存在的错误，客户端据此回退到协议版本1
+const HandshakeServiceName = "fyerrpc.Handshake"
+
+// Handshake 连接建立后交换的握手信息
+// 客户端先发送自身的握手信息，服务端回复自身的握手信息或拒绝原因，
+// 双方各自调用Negotiate得到相同的协商结果。握手消息始终使用最低协议版本编码
+type Handshake struct {
+	Version      uint8   // 支持的最高协议版本
+	MinVersion   uint8   // 支持的最低协议版本
+	Serializers  []uint8 // 支持的序列化类型
+	Compressors  []uint8 // 支持的压缩类型，不包括CompressTypeNone
+	MaxFrameSize uint32  // 接受的最大消息体大小，0表示不限制
+	AppName      string  // 应用名称
+	InstanceID   string  // 实例ID
+	Error        string  // 服务端拒绝握手时的原因
+}
+
+// 握手错误
+var (
+	ErrIncompatibleVersion = NewError("incompatible protocol version")
+	ErrNoCommonSerializer  = NewError("no common serialization type")
+	ErrUnsupportedVersion  = NewError("unsupported protocol version")
+	ErrHandshakeRejected   = NewError("handshake rejected")
+	ErrUnexpectedMessage   = NewError("unexpected message during handshake")
+)
+
+// NewHandshake 返回当前实现支持的握手信息
+func NewHandshake() *Handshake {
+	return &Handshake{
+		Version:     ProtocolVersion,
+		MinVersion:  MinProtocolVersion,
+		Serializers: SupportedSerializers(),
+		Compressors: SupportedCompressors(),
+	}
+}
+
+// Negotiate 根据本端和对端的握手信息协商连接使用的特性
+// 结果的Version为双方都支持的最高版本，Serializers和Compressors为双方都支持的类型（按本端顺序），
+// MaxFrameSize、AppName和InstanceID取自对端
+func Negotiate(local, remote *Handshake) (*Handshake, error) {
+	version := local.Version
+	if remote.Version < version {
+		version = remote.Version
+	}
+	minVersion := local.MinVersion
+	if remote.MinVersion > minVersion {
+		minVersion = remote.MinVersion
+	}
+	if version < minVersion {
+		return nil, fmt.Errorf("%w: local supports [%d, %d], remote supports [%d, %d]",
+			ErrIncompatibleVersion, local.MinVersion, local.Version, remote.MinVersion, remote.Version)
+	}
+
+	serializers := intersect(local.Serializers, remote.Serializers)
+	if len(serializers) == 0 {
+		return nil, fmt.Errorf("%w: local %v, remote %v", ErrNoCommonSerializer, local.Serializers, remote.Serializers)
+	}
+
+	return &Handshake{
+		Version:      version,
+		MinVersion:   minVersion,
+		Serializers:  serializers,
+		Compressors:  intersect(local.Compressors, remote.Compressors),
+		MaxFrameSize: remote.MaxFrameSize,
+		AppName:      remote.AppName,
+		InstanceID:   remote.InstanceID,
+	}, nil
+}
+
+// SupportsSerializer 判断协商结果是否包含指定的序列化类型
+func (h *Handshake) SupportsSerializer(serializationType uint8) bool {
+	return contains(h.Serializers, serializationType)
+}
+
+// SupportsCompressor 判断协商结果是否包含指定的压缩类型，CompressTypeNone始终支持
+func (h *Handshake) SupportsCompressor(compressType uint8) bool {
+	return compressType == CompressTypeNone || contains(h.Compressors, compressType)
+}
+
+// SupportedSerializers 返回已注册编解码器的序列化类型
+func SupportedSerializers() []uint8 {
+	var types []uint8
+	for _, t := range []uint8{SerializationTypeJSON, SerializationTypeProtobuf} {
+		if GetCodecByType(t) != nil {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// SupportedCompressors 返回已注册压缩器的压缩类型
+func SupportedCompressors() []uint8 {
+	var types []uint8
+	for _, t := range compress.Types() {
+		types = append(types, uint8(t))
+	}
+	return types
+}
+
+func intersect(a, b []uint8) []uint8 {
+	var result []uint8
+	for _, v := range a {
+		if contains(b, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func contains(values []uint8, v uint8) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package protocol
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Run("highest common feature set", func(t *testing.T) {
+		local := &Handshake{
+			Version:     3,
+			MinVersion:  1,
+			Serializers: []uint8{SerializationTypeProtobuf, SerializationTypeJSON},
+			Compressors: []uint8{CompressTypeGzip, CompressTypeZlib},
+		}
+		remote := &Handshake{
+			Version:      2,
+			MinVersion:   2,
+			Serializers:  []uint8{SerializationTypeJSON},
+			Compressors:  []uint8{CompressTypeZlib, CompressTypeDeflate},
+			MaxFrameSize: 1024,
+			AppName:      "order",
+			InstanceID:   "order-1",
+		}
+
+		negotiated, err := Negotiate(local, remote)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(2), negotiated.Version)
+		assert.Equal(t, []uint8{SerializationTypeJSON}, negotiated.Serializers)
+		assert.Equal(t, []uint8{CompressTypeZlib}, negotiated.Compressors)
+		assert.Equal(t, uint32(1024), negotiated.MaxFrameSize)
+		assert.Equal(t, "order", negotiated.AppName)
+		assert.Equal(t, "order-1", negotiated.InstanceID)
+
+		assert.True(t, negotiated.SupportsCompressor(CompressTypeNone))
+		assert.False(t, negotiated.SupportsCompressor(CompressTypeGzip))
+		assert.False(t, negotiated.SupportsSerializer(SerializationTypeProtobuf))
+	})
+
+	t.Run("incompatible version", func(t *testing.T) {
+		local := &Handshake{Version: 1, MinVersion: 1, Serializers: []uint8{SerializationTypeJSON}}
+		remote := &Handshake{Version: 3, MinVersion: 2, Serializers: []uint8{SerializationTypeJSON}}
+
+		_, err := Negotiate(local, remote)
+		assert.ErrorIs(t, err, ErrIncompatibleVersion)
+		assert.Contains(t, err.Error(), "remote supports [2, 3]")
+	})
+
+	t.Run("no common serializer", func(t *testing.T) {
+		local := &Handshake{Version: 1, MinVersion: 1, Serializers: []uint8{SerializationTypeJSON}}
+		remote := &Handshake{Version: 1, MinVersion: 1, Serializers: []uint8{SerializationTypeProtobuf}}
+
+		_, err := Negotiate(local, remote)
+		assert.ErrorIs(t, err, ErrNoCommonSerializer)
+	})
+
+	t.Run("unsupported version on decode", func(t *testing.T) {
+		proto := &DefaultProtocol{}
+		msg := &Message{
+			Header: Header{
+				MagicNumber:       MagicNumber,
+				Version:           ProtocolVersion + 1,
+				MessageType:       TypeRequest,
+				SerializationType: SerializationTypeJSON,
+				MessageID:         1,
+			},
+			Metadata: &Metadata{ServiceName: "TestService", MethodName: "TestMethod"},
+			Payload:  []byte(`{}`),
+		}
+
+		buf := new(bytes.Buffer)
+		require.NoError(t, proto.EncodeMessage(msg, buf))
+		_, err := proto.DecodeMessage(buf)
+		assert.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+}
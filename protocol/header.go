@@ -7,6 +7,22 @@ const MagicNumber uint16 = 0x3f3f
 const (
 	TypeRequest  = uint8(0x01) // 请求消息
 	TypeResponse = uint8(0x02) // 响应消息
+	TypeCancel   = uint8(0x03) // 取消消息，通知服务端放弃MessageID对应的请求
+	TypeGoAway   = uint8(0x04) // 关闭通知，服务端即将关闭，客户端不应再发送新请求
+
+	// 流消息，同一个流的所有消息使用相同的MessageID
+	TypeStreamOpen      = uint8(0x05) // 打开流，元数据中携带服务名和方法名
+	TypeStreamData      = uint8(0x06) // 流数据，消息体为一条序列化后的消息
+	TypeStreamHalfClose = uint8(0x07) // 发送方不再发送数据，仍可继续接收
+	TypeStreamClose     = uint8(0x08) // 服务端结束流，元数据中携带最终状态
+
+	// 心跳消息，连接空闲时用于检测对端是否存活
+	TypePing = uint8(0x09) // 心跳请求，收到后应立即回复TypePong
+	TypePong = uint8(0x0a) // 心跳响应，MessageID与对应的TypePing相同
+
+	TypeHandshake = uint8(0x0b) // 握手消息，连接建立后的第一条消息，消息体为JSON编码的Handshake
+
+	TypeOneway = uint8(0x0c) // 单向请求，服务端处理后不发送响应
 )
 
 // 序列化类型
@@ -17,8 +33,10 @@ const (
 
 // 压缩类型
 const (
-	CompressTypeNone = uint8(0x00) // 不压缩
-	CompressTypeGzip = uint8(0x01) // Gzip压缩
+	CompressTypeNone    = uint8(0x00) // 不压缩
+	CompressTypeGzip    = uint8(0x01) // Gzip压缩
+	CompressTypeDeflate = uint8(0x02) // Deflate压缩
+	CompressTypeZlib    = uint8(0x03) // Zlib压缩
 )
 
 // Header 协议头部结构
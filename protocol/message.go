@@ -5,7 +5,7 @@ package protocol
 // +------------------+
 // |     Header      |  消息头部（固定22字节）
 // +------------------+
-// |    Metadata     |  元数据(可变长度)，包含服务名、方法名等信息
+// |    Metadata     |  元数据(可变长度)，包含服务名、方法名等信息，始终使用JSON编码
 // +------------------+
 // |    Payload      |  消息体(可变长度)，包含请求参数或响应结果
 // +------------------+
@@ -13,6 +13,19 @@ type Message struct {
 	Header   Header    // 消息头部
 	Metadata *Metadata // 元数据
 	Payload  []byte    // 消息体
+
+	buf []byte // 解码时从缓冲池获取的缓冲区，Payload指向其中的一段
+}
+
+// Release 将解码消息时使用的缓冲区归还给缓冲池
+// 调用后不能再访问Payload；不调用Release也是安全的，缓冲区会被垃圾回收
+func (m *Message) Release() {
+	if m.buf == nil {
+		return
+	}
+	PutBuffer(m.buf)
+	m.buf = nil
+	m.Payload = nil
 }
 
 // Error 实现接口
@@ -25,10 +38,27 @@ func (m Message) Error() string {
 type Metadata struct {
 	ServiceName string            // 服务名称
 	MethodName  string            // 方法名称
+	Version     string            // 服务版本，为空时使用服务端的默认版本(仅请求消息使用)
 	Error       string            // 错误信息(仅响应消息使用)
+	Status      *Status           // 结构化错误状态(仅响应消息使用)，Error保留为其错误信息
+	Timeout     int64             // 调用剩余超时时间(毫秒)，0表示不限制(仅请求消息使用)
 	Extra       map[string]string // 额外的元数据，如trace_id等
 }
 
+// Status 调用失败时的结构化状态
+type Status struct {
+	Code    int            // 错误码
+	Message string         // 错误信息
+	Details []*ErrorDetail // 可选的错误详情
+}
+
+// ErrorDetail 错误详情
+// Type 为详情的类型名，Value 为详情序列化后的内容
+type ErrorDetail struct {
+	Type  string
+	Value []byte
+}
+
 // RequestMessage 请求消息
 // type RequestMessage struct {
 // 	*Message
@@ -1,10 +1,14 @@
 package protocol
 
 import (
+	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
+	"net"
 
 	"github.com/fyerfyer/fyer-rpc/protocol/codec"
+	"github.com/fyerfyer/fyer-rpc/protocol/compress"
 )
 
 // Protocol 协议编解码接口
@@ -14,138 +18,216 @@ type Protocol interface {
 }
 
 // DefaultProtocol 默认协议实现
-type DefaultProtocol struct{}
+// MaxMetadataSize和MaxPayloadSize限制解码时接受的元数据和消息体大小，为0时不限制
+type DefaultProtocol struct {
+	MaxMetadataSize uint32
+	MaxPayloadSize  uint32
+}
+
+// maxCopiedPayload 消息体不超过该大小时复制到编码缓冲区，与头部一起写出；
+// 更大的消息体不复制，通过net.Buffers写出，写入网络连接时仍只有一次系统调用(writev)
+const maxCopiedPayload = 64 << 10
 
 // EncodeMessage 编码消息
+// 当CompressType不为CompressTypeNone时，消息体在写入前被压缩，元数据不压缩
+// 整条消息先写入缓冲区，再通过一次Write写出
 func (p *DefaultProtocol) EncodeMessage(message *Message, writer io.Writer) error {
-	// 写入头部各个字段
-	if err := binary.Write(writer, binary.BigEndian, message.Header.MagicNumber); err != nil {
-		return err
-	}
-	if err := binary.Write(writer, binary.BigEndian, message.Header.Version); err != nil {
-		return err
-	}
-	if err := binary.Write(writer, binary.BigEndian, message.Header.MessageType); err != nil {
-		return err
-	}
-	if err := binary.Write(writer, binary.BigEndian, message.Header.CompressType); err != nil {
-		return err
-	}
-	if err := binary.Write(writer, binary.BigEndian, message.Header.SerializationType); err != nil {
-		return err
-	}
-	if err := binary.Write(writer, binary.BigEndian, message.Header.MessageID); err != nil {
-		return err
+	// 压缩消息体
+	payload := message.Payload
+	if message.Header.CompressType != CompressTypeNone && len(payload) > 0 {
+		compressor := GetCompressorByType(message.Header.CompressType)
+		if compressor == nil {
+			return ErrUnsupportedCompressor
+		}
+
+		var err error
+		payload, err = compressor.Compress(payload)
+		if err != nil {
+			return err
+		}
 	}
 
-	// 序列化元数据
+	// 序列化元数据，元数据始终使用JSON编码，SerializationType只作用于消息体
 	var metadataBytes []byte
-	var err error
 	if message.Metadata != nil {
-		codec := GetCodecByType(message.Header.SerializationType)
-		if codec == nil {
-			return ErrUnsupportedSerializer
-		}
-
-		metadataBytes, err = codec.Encode(message.Metadata)
+		var err error
+		metadataBytes, err = codec.GetCodec(codec.JSON).Encode(message.Metadata)
 		if err != nil {
 			return err
 		}
 	}
-
-	// 写入元数据长度
 	message.Header.MetadataSize = uint32(len(metadataBytes))
-	if err := binary.Write(writer, binary.BigEndian, message.Header.MetadataSize); err != nil {
-		return err
-	}
+	message.Header.PayloadSize = uint32(len(payload))
 
-	// 写入消息体长度
-	message.Header.PayloadSize = uint32(len(message.Payload))
-	if err := binary.Write(writer, binary.BigEndian, message.Header.PayloadSize); err != nil {
-		return err
-	}
+	buf := getEncodeBuffer()
+	defer putEncodeBuffer(buf)
 
-	// 写入元数据
-	if len(metadataBytes) > 0 {
-		if _, err := writer.Write(metadataBytes); err != nil {
-			return err
-		}
-	}
+	// 写入头部和元数据
+	var header [HeaderSize]byte
+	putHeader(header[:], &message.Header)
+	buf.Write(header[:])
+	buf.Write(metadataBytes)
 
-	// 写入消息体
-	if len(message.Payload) > 0 {
-		if _, err := writer.Write(message.Payload); err != nil {
-			return err
-		}
+	if len(payload) > maxCopiedPayload {
+		buffers := net.Buffers{buf.Bytes(), payload}
+		_, err := buffers.WriteTo(writer)
+		return err
 	}
 
-	return nil
+	// 写入消息体
+	buf.Write(payload)
+	_, err := writer.Write(buf.Bytes())
+	return err
 }
 
 // DecodeMessage 解码消息
+// 头部通过一次读取解析，元数据和消息体读入缓冲池中的同一个缓冲区，
+// 消息使用完毕后可以调用Message.Release归还缓冲区
 func (p *DefaultProtocol) DecodeMessage(reader io.Reader) (*Message, error) {
-	message := &Message{
-		Header: Header{},
-	}
-
-	// 读取头部各个字段
-	if err := binary.Read(reader, binary.BigEndian, &message.Header.MagicNumber); err != nil {
-		return nil, err
-	}
-	if message.Header.MagicNumber != MagicNumber {
-		return nil, ErrInvalidMagic
-	}
+	message := &Message{}
 
-	if err := binary.Read(reader, binary.BigEndian, &message.Header.Version); err != nil {
-		return nil, err
-	}
-	if err := binary.Read(reader, binary.BigEndian, &message.Header.MessageType); err != nil {
-		return nil, err
+	// 读取头部
+	header := GetBuffer(HeaderSize)
+	_, err := io.ReadFull(reader, header)
+	if err == nil {
+		err = parseHeader(header, &message.Header)
 	}
-	if err := binary.Read(reader, binary.BigEndian, &message.Header.CompressType); err != nil {
+	PutBuffer(header)
+	if err != nil {
 		return nil, err
 	}
-	if err := binary.Read(reader, binary.BigEndian, &message.Header.SerializationType); err != nil {
-		return nil, err
-	}
-	if err := binary.Read(reader, binary.BigEndian, &message.Header.MessageID); err != nil {
+
+	// 检查大小限制，超限时丢弃消息体，避免按对端声明的长度分配内存
+	if err := p.checkFrameSize(message.Header, reader); err != nil {
 		return nil, err
 	}
-	if err := binary.Read(reader, binary.BigEndian, &message.Header.MetadataSize); err != nil {
-		return nil, err
+
+	metadataSize := int(message.Header.MetadataSize)
+	bodySize := metadataSize + int(message.Header.PayloadSize)
+	if bodySize == 0 {
+		return message, nil
 	}
-	if err := binary.Read(reader, binary.BigEndian, &message.Header.PayloadSize); err != nil {
+
+	body := GetBuffer(bodySize)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		PutBuffer(body)
 		return nil, err
 	}
 
-	// 读取元数据
-	if message.Header.MetadataSize > 0 {
-		metadataBytes := make([]byte, message.Header.MetadataSize)
-		if _, err := io.ReadFull(reader, metadataBytes); err != nil {
+	// 解析元数据
+	if metadataSize > 0 {
+		message.Metadata = &Metadata{}
+		if err := codec.GetCodec(codec.JSON).Decode(body[:metadataSize], message.Metadata); err != nil {
+			PutBuffer(body)
 			return nil, err
 		}
+	}
 
-		codec := GetCodecByType(message.Header.SerializationType)
-		if codec == nil {
-			return nil, ErrUnsupportedSerializer
+	// 读取消息体
+	if message.Header.PayloadSize == 0 {
+		PutBuffer(body)
+		return message, nil
+	}
+	payload := body[metadataSize:]
+
+	// 解压消息体，解压结果是新分配的内存，原缓冲区可以立即归还
+	if message.Header.CompressType != CompressTypeNone {
+		compressor := GetCompressorByType(message.Header.CompressType)
+		if compressor == nil {
+			PutBuffer(body)
+			return nil, ErrUnsupportedCompressor
 		}
 
-		message.Metadata = &Metadata{}
-		if err := codec.Decode(metadataBytes, message.Metadata); err != nil {
+		payload, err = p.decompress(message.Header, compressor, payload)
+		PutBuffer(body)
+		if err != nil {
 			return nil, err
 		}
+		message.Payload = payload
+		return message, nil
 	}
 
-	// 读取消息体
-	if message.Header.PayloadSize > 0 {
-		payload := make([]byte, message.Header.PayloadSize)
-		if _, err := io.ReadFull(reader, payload); err != nil {
+	message.Payload = payload
+	message.buf = body
+	return message, nil
+}
+
+// decompress 解压消息体并检查解压后的大小
+// 压缩器支持流式解压时最多读取MaxPayloadSize+1字节，超限立即停止，避免压缩炸弹耗尽内存
+func (p *DefaultProtocol) decompress(header Header, compressor compress.Compressor, data []byte) ([]byte, error) {
+	limit := int64(p.MaxPayloadSize)
+	var payload []byte
+	if rd, ok := compressor.(compress.ReaderDecompressor); ok && limit > 0 {
+		reader, err := rd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		if payload, err = io.ReadAll(io.LimitReader(reader, limit+1)); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if payload, err = compressor.Decompress(data); err != nil {
 			return nil, err
 		}
-		message.Payload = payload
 	}
 
-	return message, nil
+	if limit > 0 && int64(len(payload)) > limit {
+		return nil, &FrameTooLargeError{Header: header, Field: "payload", Size: uint32(len(payload)), Limit: p.MaxPayloadSize}
+	}
+	return payload, nil
+}
+
+// putHeader 按大端序将头部写入buf
+func putHeader(buf []byte, header *Header) {
+	binary.BigEndian.PutUint16(buf[0:2], header.MagicNumber)
+	buf[2] = header.Version
+	buf[3] = header.MessageType
+	buf[4] = header.CompressType
+	buf[5] = header.SerializationType
+	binary.BigEndian.PutUint64(buf[6:14], header.MessageID)
+	binary.BigEndian.PutUint32(buf[14:18], header.MetadataSize)
+	binary.BigEndian.PutUint32(buf[18:22], header.PayloadSize)
+}
+
+// parseHeader 从buf解析头部并校验魔数和协议版本
+func parseHeader(buf []byte, header *Header) error {
+	header.MagicNumber = binary.BigEndian.Uint16(buf[0:2])
+	if header.MagicNumber != MagicNumber {
+		return ErrInvalidMagic
+	}
+	header.Version = buf[2]
+	if header.Version < MinProtocolVersion || header.Version > ProtocolVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, header.Version)
+	}
+	header.MessageType = buf[3]
+	header.CompressType = buf[4]
+	header.SerializationType = buf[5]
+	header.MessageID = binary.BigEndian.Uint64(buf[6:14])
+	header.MetadataSize = binary.BigEndian.Uint32(buf[14:18])
+	header.PayloadSize = binary.BigEndian.Uint32(buf[18:22])
+	return nil
+}
+
+// checkFrameSize 检查消息大小是否超过限制
+// 超限时读取并丢弃整个消息体后返回FrameTooLargeError，丢弃失败时返回读取错误
+func (p *DefaultProtocol) checkFrameSize(header Header, reader io.Reader) error {
+	var tooLarge *FrameTooLargeError
+	switch {
+	case p.MaxMetadataSize > 0 && header.MetadataSize > p.MaxMetadataSize:
+		tooLarge = &FrameTooLargeError{Header: header, Field: "metadata", Size: header.MetadataSize, Limit: p.MaxMetadataSize}
+	case p.MaxPayloadSize > 0 && header.PayloadSize > p.MaxPayloadSize:
+		tooLarge = &FrameTooLargeError{Header: header, Field: "payload", Size: header.PayloadSize, Limit: p.MaxPayloadSize}
+	default:
+		return nil
+	}
+
+	bodySize := int64(header.MetadataSize) + int64(header.PayloadSize)
+	if _, err := io.CopyN(io.Discard, reader, bodySize); err != nil {
+		return err
+	}
+	return tooLarge
 }
 
 // GetCodecByType 添加工具函数
@@ -159,3 +241,11 @@ func GetCodecByType(serializationType uint8) codec.Codec {
 		return nil
 	}
 }
+
+// GetCompressorByType 根据协议头中的压缩类型从压缩器注册表中获取压缩器
+func GetCompressorByType(compressType uint8) compress.Compressor {
+	if compressType == CompressTypeNone {
+		return nil
+	}
+	return compress.GetCompressor(compress.Type(compressType))
+}
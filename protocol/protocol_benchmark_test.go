@@ -64,7 +64,9 @@ func BenchmarkProtocolDecode(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		reader := bytes.NewReader(encodedData)
-		proto.DecodeMessage(reader)
+		if decoded, err := proto.DecodeMessage(reader); err == nil {
+			decoded.Release()
+		}
 	}
 }
 
@@ -144,7 +146,9 @@ func BenchmarkProtocolDecodeWithDifferentSizes(b *testing.B) {
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
 				reader := bytes.NewReader(encodedData)
-				proto.DecodeMessage(reader)
+				if decoded, err := proto.DecodeMessage(reader); err == nil {
+					decoded.Release()
+				}
 			}
 		})
 	}
@@ -225,6 +229,47 @@ func BenchmarkProtocolRoundTrip(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		buf := new(bytes.Buffer)
 		proto.EncodeMessage(msg, buf)
-		proto.DecodeMessage(buf)
+		if decoded, err := proto.DecodeMessage(buf); err == nil {
+			decoded.Release()
+		}
 	}
 }
+
+// countingWriter 统计Write调用次数，对应写入网络连接时的系统调用次数
+type countingWriter struct {
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
+
+func BenchmarkProtocolEncodeWrites(b *testing.B) {
+	proto := &DefaultProtocol{}
+
+	msg := &Message{
+		Header: Header{
+			MagicNumber:       MagicNumber,
+			Version:           1,
+			MessageType:       TypeRequest,
+			CompressType:      CompressTypeNone,
+			SerializationType: SerializationTypeJSON,
+			MessageID:         1,
+		},
+		Metadata: &Metadata{
+			ServiceName: "TestService",
+			MethodName:  "TestMethod",
+		},
+		Payload: []byte(`{"test":"data"}`),
+	}
+
+	writer := &countingWriter{}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		proto.EncodeMessage(msg, writer)
+	}
+	b.ReportMetric(float64(writer.writes)/float64(b.N), "writes/op")
+}
@@ -2,9 +2,12 @@ package protocol
 
 import (
 	"bytes"
+	"compress/gzip"
+	"runtime"
 	"testing"
 
 	_ "github.com/fyerfyer/fyer-rpc/protocol/codec"
+	"github.com/fyerfyer/fyer-rpc/protocol/compress"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -53,4 +56,261 @@ func TestProtocolEncodeDecode(t *testing.T) {
 		// 验证payload
 		assert.Equal(t, msg.Payload, decoded.Payload)
 	})
+	t.Run("protobuf payload keeps json metadata", func(t *testing.T) {
+		msg := &Message{
+			Header: Header{
+				MagicNumber:       MagicNumber,
+				Version:           1,
+				MessageType:       TypeRequest,
+				CompressType:      CompressTypeNone,
+				SerializationType: SerializationTypeProtobuf,
+				MessageID:         3,
+			},
+			Metadata: &Metadata{
+				ServiceName: "TestService",
+				MethodName:  "TestMethod",
+			},
+			Payload: []byte{0x08, 0x7b},
+		}
+
+		buf := new(bytes.Buffer)
+		require.NoError(t, proto.EncodeMessage(msg, buf))
+
+		decoded, err := proto.DecodeMessage(buf)
+		require.NoError(t, err)
+		assert.Equal(t, SerializationTypeProtobuf, decoded.Header.SerializationType)
+		assert.Equal(t, "TestService", decoded.Metadata.ServiceName)
+		assert.Equal(t, msg.Payload, decoded.Payload)
+	})
+
+	t.Run("compressed payload", func(t *testing.T) {
+		payload := bytes.Repeat([]byte(`{"test":"data"}`), 100)
+
+		for _, compressType := range []uint8{CompressTypeGzip, CompressTypeDeflate, CompressTypeZlib} {
+			msg := &Message{
+				Header: Header{
+					MagicNumber:       MagicNumber,
+					Version:           1,
+					MessageType:       TypeRequest,
+					CompressType:      compressType,
+					SerializationType: SerializationTypeJSON,
+					MessageID:         2,
+				},
+				Metadata: &Metadata{ServiceName: "TestService", MethodName: "TestMethod"},
+				Payload:  payload,
+			}
+
+			buf := new(bytes.Buffer)
+			require.NoError(t, proto.EncodeMessage(msg, buf))
+			assert.Less(t, int(msg.Header.PayloadSize), len(payload))
+
+			decoded, err := proto.DecodeMessage(buf)
+			require.NoError(t, err)
+			assert.Equal(t, compressType, decoded.Header.CompressType)
+			assert.Equal(t, payload, decoded.Payload)
+		}
+	})
+
+	t.Run("unsupported compress type", func(t *testing.T) {
+		msg := &Message{
+			Header: Header{
+				MagicNumber:       MagicNumber,
+				Version:           1,
+				MessageType:       TypeRequest,
+				CompressType:      0x7f,
+				SerializationType: SerializationTypeJSON,
+			},
+			Payload: []byte("data"),
+		}
+
+		err := proto.EncodeMessage(msg, new(bytes.Buffer))
+		assert.ErrorIs(t, err, ErrUnsupportedCompressor)
+	})
+
+	t.Run("registered compressor", func(t *testing.T) {
+		const compressTypeReverse = uint8(0x40)
+		compress.RegisterCompressor(compress.Type(compressTypeReverse), reverseCompressor{})
+		assert.Contains(t, SupportedCompressors(), compressTypeReverse)
+
+		msg := &Message{
+			Header: Header{
+				MagicNumber:       MagicNumber,
+				Version:           1,
+				MessageType:       TypeRequest,
+				CompressType:      compressTypeReverse,
+				SerializationType: SerializationTypeJSON,
+			},
+			Payload: []byte("abc"),
+		}
+		buf := new(bytes.Buffer)
+		require.NoError(t, proto.EncodeMessage(msg, buf))
+		assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("cba")))
+
+		decoded, err := proto.DecodeMessage(buf)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("abc"), decoded.Payload)
+	})
+
+	t.Run("frame size limits", func(t *testing.T) {
+		limited := &DefaultProtocol{MaxMetadataSize: 256, MaxPayloadSize: 16}
+		newMsg := func(id uint64, metadata *Metadata, payload []byte) *Message {
+			return &Message{
+				Header: Header{
+					MagicNumber:       MagicNumber,
+					Version:           1,
+					MessageType:       TypeRequest,
+					SerializationType: SerializationTypeJSON,
+					MessageID:         id,
+				},
+				Metadata: metadata,
+				Payload:  payload,
+			}
+		}
+
+		buf := new(bytes.Buffer)
+		require.NoError(t, proto.EncodeMessage(newMsg(1, nil, bytes.Repeat([]byte("x"), 17)), buf))
+		require.NoError(t, proto.EncodeMessage(newMsg(2, &Metadata{ServiceName: string(bytes.Repeat([]byte("s"), 256))}, nil), buf))
+		require.NoError(t, proto.EncodeMessage(newMsg(3, &Metadata{ServiceName: "ok"}, []byte("small")), buf))
+
+		// 消息体超限
+		_, err := limited.DecodeMessage(buf)
+		require.ErrorIs(t, err, ErrFrameTooLarge)
+		var tooLarge *FrameTooLargeError
+		require.ErrorAs(t, err, &tooLarge)
+		assert.Equal(t, uint64(1), tooLarge.Header.MessageID)
+		assert.Equal(t, "payload", tooLarge.Field)
+		assert.Equal(t, uint32(17), tooLarge.Size)
+
+		// 元数据超限
+		_, err = limited.DecodeMessage(buf)
+		require.ErrorAs(t, err, &tooLarge)
+		assert.Equal(t, uint64(2), tooLarge.Header.MessageID)
+		assert.Equal(t, "metadata", tooLarge.Field)
+
+		// 超限消息被丢弃后仍可读取后续消息
+		decoded, err := limited.DecodeMessage(buf)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), decoded.Header.MessageID)
+		assert.Equal(t, []byte("small"), decoded.Payload)
+	})
+
+	t.Run("decompressed payload limit", func(t *testing.T) {
+		msg := &Message{
+			Header: Header{
+				MagicNumber:       MagicNumber,
+				Version:           1,
+				MessageType:       TypeRequest,
+				CompressType:      CompressTypeGzip,
+				SerializationType: SerializationTypeJSON,
+			},
+			Payload: bytes.Repeat([]byte("a"), 4096),
+		}
+		buf := new(bytes.Buffer)
+		require.NoError(t, proto.EncodeMessage(msg, buf))
+		require.Less(t, buf.Len(), 1024)
+
+		limited := &DefaultProtocol{MaxPayloadSize: 1024}
+		_, err := limited.DecodeMessage(buf)
+		assert.ErrorIs(t, err, ErrFrameTooLarge)
+	})
+
+	t.Run("gzip bomb", func(t *testing.T) {
+		// 16MB的零字节压缩后只有十几KB
+		var bomb bytes.Buffer
+		writer, err := gzip.NewWriterLevel(&bomb, gzip.BestCompression)
+		require.NoError(t, err)
+		zeros := make([]byte, 1<<20)
+		for i := 0; i < 16; i++ {
+			_, err := writer.Write(zeros)
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+		require.Less(t, bomb.Len(), 64<<10)
+
+		// 直接构造压缩类型为gzip的帧，绕过编码时的压缩
+		msg := &Message{
+			Header: Header{
+				MagicNumber:       MagicNumber,
+				Version:           1,
+				MessageType:       TypeRequest,
+				SerializationType: SerializationTypeJSON,
+				MessageID:         9,
+			},
+			Payload: bomb.Bytes(),
+		}
+		buf := new(bytes.Buffer)
+		require.NoError(t, proto.EncodeMessage(msg, buf))
+		frame := buf.Bytes()
+		frame[4] = CompressTypeGzip
+
+		limited := &DefaultProtocol{MaxPayloadSize: 256 << 10}
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err = limited.DecodeMessage(bytes.NewReader(frame))
+		runtime.ReadMemStats(&after)
+
+		var tooLarge *FrameTooLargeError
+		require.ErrorAs(t, err, &tooLarge)
+		assert.Equal(t, uint64(9), tooLarge.Header.MessageID)
+		assert.Equal(t, "payload", tooLarge.Field)
+		// 解压在超过限制后立即停止，不会分配完整的16MB
+		assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(4<<20))
+	})
+
+	t.Run("single write per frame", func(t *testing.T) {
+		msg := &Message{
+			Header: Header{
+				MagicNumber:       MagicNumber,
+				Version:           1,
+				MessageType:       TypeRequest,
+				SerializationType: SerializationTypeJSON,
+				MessageID:         7,
+			},
+			Metadata: &Metadata{ServiceName: "TestService"},
+			Payload:  []byte(`{"test":"data"}`),
+		}
+
+		writer := &recordingWriter{}
+		require.NoError(t, proto.EncodeMessage(msg, writer))
+		require.Len(t, writer.writes, 1)
+		assert.Len(t, writer.writes[0], HeaderSize+int(msg.Header.MetadataSize)+len(msg.Payload))
+
+		// 归还缓冲区后再次解码不受影响
+		decoded, err := proto.DecodeMessage(bytes.NewReader(writer.writes[0]))
+		require.NoError(t, err)
+		assert.Equal(t, msg.Payload, decoded.Payload)
+		decoded.Release()
+		assert.Nil(t, decoded.Payload)
+		decoded.Release()
+
+		decoded, err = proto.DecodeMessage(bytes.NewReader(writer.writes[0]))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), decoded.Header.MessageID)
+		assert.Equal(t, msg.Payload, decoded.Payload)
+	})
+}
+
+// recordingWriter 记录每次Write写入的数据
+type recordingWriter struct {
+	writes [][]byte
 }
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+// reverseCompressor 将数据反转的测试压缩器
+type reverseCompressor struct{}
+
+func (reverseCompressor) Compress(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[len(data)-1-i] = b
+	}
+	return out, nil
+}
+
+func (c reverseCompressor) Decompress(data []byte) ([]byte, error) { return c.Compress(data) }
+
+func (reverseCompressor) Name() string { return "reverse" }
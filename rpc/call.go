@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/fyerfyer/fyer-rpc/utils"
+)
+
+// Call 一次异步调用，调用完成后Call被发送到Done
+type Call struct {
+	ServiceName string      // 服务名称
+	MethodName  string      // 方法名称
+	Args        interface{} // 请求参数
+	Reply       interface{} // 响应，调用成功后解码到这里
+	Error       error       // 调用完成后的错误
+	Done        chan *Call  // 调用完成时接收Call
+}
+
+// done 通知调用完成，Done已满时丢弃通知
+func (call *Call) done() {
+	select {
+	case call.Done <- call:
+	default:
+		utils.Warn("Discarding reply of %s.%s due to insufficient Done channel capacity", call.ServiceName, call.MethodName)
+	}
+}
+
+// Go 异步调用远程方法，立即返回表示该调用的Call，调用完成后Call被发送到done
+// done为nil时创建新的通道，传入无缓冲的通道会panic；
+// 多个调用共用done时需要有足够的缓冲，通道已满时该调用的完成通知被丢弃并记录警告日志
+// ctx结束时调用以对应的错误完成，服务端的处理也随之取消
+func (c *Client) Go(ctx context.Context, serviceName, methodName string, args, reply interface{}, done chan *Call, opts ...CallOption) *Call {
+	if done == nil {
+		done = make(chan *Call, 10)
+	} else if cap(done) == 0 {
+		panic("rpc: done channel is unbuffered")
+	}
+
+	call := &Call{
+		ServiceName: serviceName,
+		MethodName:  methodName,
+		Args:        args,
+		Reply:       reply,
+		Done:        done,
+	}
+	go func() {
+		call.Error = c.Invoke(ctx, serviceName, methodName, args, reply, opts...)
+		call.done()
+	}()
+	return call
+}
@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncCall(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.RegisterService(&BenchService{}))
+	require.NoError(t, server.RegisterService(&SlowService{done: make(chan error, 10)}))
+	addr := startTestServer(t, server)
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	t.Run("fan out", func(t *testing.T) {
+		done := make(chan *Call, 5)
+		calls := make(map[*Call]string)
+		for i := 0; i < 5; i++ {
+			value := fmt.Sprintf("v%d", i)
+			call := client.Go(context.Background(), "BenchService", "Echo", &BenchRequest{Value: value}, &BenchResponse{}, done)
+			calls[call] = value
+		}
+
+		for i := 0; i < 5; i++ {
+			select {
+			case call := <-done:
+				require.NoError(t, call.Error)
+				assert.Equal(t, calls[call], call.Reply.(*BenchResponse).Value)
+			case <-time.After(2 * time.Second):
+				t.Fatal("async call did not complete")
+			}
+		}
+	})
+
+	t.Run("default done channel", func(t *testing.T) {
+		call := client.Go(context.Background(), "BenchService", "Echo", &BenchRequest{Value: "single"}, &BenchResponse{}, nil)
+		completed := <-call.Done
+		assert.Same(t, call, completed)
+		require.NoError(t, completed.Error)
+		assert.Equal(t, "single", completed.Reply.(*BenchResponse).Value)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		call := <-client.Go(context.Background(), "NoSuchService", "Echo", &BenchRequest{}, &BenchResponse{}, nil).Done
+		assert.ErrorIs(t, call.Error, ErrNotFound)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		call := client.Go(ctx, "SlowService", "Wait", &SlowRequest{Delay: time.Minute}, &SlowResponse{}, nil)
+		cancel()
+
+		select {
+		case <-call.Done:
+			assert.ErrorIs(t, call.Error, ErrCanceled)
+		case <-time.After(2 * time.Second):
+			t.Fatal("canceled call did not complete")
+		}
+	})
+
+	t.Run("unbuffered done channel", func(t *testing.T) {
+		assert.Panics(t, func() {
+			client.Go(context.Background(), "BenchService", "Echo", &BenchRequest{}, &BenchResponse{}, make(chan *Call))
+		})
+	})
+}
@@ -2,20 +2,59 @@ package rpc
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/fyerfyer/fyer-rpc/cluster/failover"
+	"github.com/fyerfyer/fyer-rpc/config"
 	"github.com/fyerfyer/fyer-rpc/naming"
 	"github.com/fyerfyer/fyer-rpc/protocol"
-	"github.com/fyerfyer/fyer-rpc/protocol/codec"
-	"net"
-	"sync/atomic"
+	"github.com/fyerfyer/fyer-rpc/transport"
+	"github.com/fyerfyer/fyer-rpc/utils"
 )
 
+var (
+	// ErrShutdown 连接已关闭时发起调用返回的错误
+	ErrShutdown = NewRPCError(ErrCodeUnavailable, "connection is shut down")
+	// ErrDraining 服务端已发送GoAway，连接不再接受新调用
+	ErrDraining = NewRPCError(ErrCodeUnavailable, "connection is draining: server is shutting down")
+)
+
+// Client RPC客户端，同一连接上的并发调用通过MessageID进行多路复用
 type Client struct {
-	conn            *Connection
-	messageID       uint64
-	failoverConfig  *failover.Config                 // 故障转移配置
-	failoverHandler *failover.DefaultFailoverHandler // 故障转移处理器
-	enableFailover  bool                             // 是否启用故障转移
+	conn              *Connection
+	messageID         uint64
+	mu                sync.Mutex
+	pending           map[uint64]chan *protocol.Message // 等待响应的调用，按MessageID索引
+	streams           map[uint64]*clientStream          // 进行中的流，按MessageID索引
+	closing           bool                              // 用户主动关闭
+	shutdown          bool                              // 读循环已退出，连接不可用
+	draining          bool                              // 收到服务端GoAway，等待中的调用完成后连接将被关闭
+	serializationType uint8                             // 默认的请求序列化类型
+	compressType      uint8                             // 请求压缩类型
+	compressMinSize   int                               // 启用压缩的最小消息体大小
+	keepAliveTime     time.Duration                     // 连接空闲多久发送心跳，为0时不发送
+	keepAliveCount    int                               // 连续多少次心跳未响应时关闭连接
+	maxHeaderBytes    int                               // 接受的最大响应元数据大小
+	maxResponseSize   int                               // 接受的最大响应消息体大小
+	handshake         bool                              // 是否在连接建立后握手
+	handshakeTimeout  time.Duration                     // 等待服务端握手回复的时间
+	appName           string                            // 握手时发送的应用名称
+	instanceID        string                            // 握手时发送的实例ID
+	peer              *protocol.Handshake               // 握手协商结果，未握手时为nil
+	tlsConfig         *tls.Config                       // TLS配置，为nil时使用明文连接
+	credentials       PerRPCCredentials                 // 每次调用附加的认证信息
+	dialer            transport.Dialer                  // 自定义的连接方式，为nil时按地址的传输方式连接
+	interceptors      []ClientInterceptor               // 客户端拦截器
+	interceptor       ClientInterceptor                 // 组合后的拦截器链
+	failoverConfig    *failover.Config                  // 故障转移配置
+	failoverHandler   *failover.DefaultFailoverHandler  // 故障转移处理器
+	enableFailover    bool                              // 是否启用故障转移
 }
 
 // ClientOption 客户端配置选项
@@ -33,90 +72,749 @@ func WithFailover(config *failover.Config) ClientOption {
 	}
 }
 
-func NewClient(address string, options ...ClientOption) (*Client, error) {
-	conn, err := net.Dial("tcp", address)
-	if err != nil {
-		return nil, NewRPCError(ErrCodeInternal, "failed to connect: "+err.Error())
+// WithSerialization 设置客户端默认的序列化类型
+func WithSerialization(serializationType uint8) ClientOption {
+	return func(c *Client) {
+		c.serializationType = serializationType
+	}
+}
+
+// WithCompression 设置请求消息体的压缩类型，消息体小于minSize字节时不压缩
+func WithCompression(compressType uint8, minSize int) ClientOption {
+	return func(c *Client) {
+		c.compressType = compressType
+		c.compressMinSize = minSize
+	}
+}
+
+// WithKeepAlive 设置连接心跳，连接空闲interval后发送心跳，连续maxMissed次未响应时关闭连接
+// interval为0时关闭心跳；心跳只在与服务端握手成功的连接上发送
+func WithKeepAlive(interval time.Duration, maxMissed int) ClientOption {
+	return func(c *Client) {
+		c.keepAliveTime = interval
+		if maxMissed > 0 {
+			c.keepAliveCount = maxMissed
+		}
+	}
+}
+
+// WithMessageSizeLimit 设置接受的最大响应元数据和消息体大小，为0时不限制
+// 超限的响应被丢弃，对应的调用返回ErrCodeResourceExhausted错误
+func WithMessageSizeLimit(maxHeaderBytes, maxResponseSize int) ClientOption {
+	return func(c *Client) {
+		c.maxHeaderBytes = maxHeaderBytes
+		c.maxResponseSize = maxResponseSize
+	}
+}
+
+// WithIdentity 设置握手时发送的应用名称和实例ID，instanceID为空时使用"主机名-进程号"
+func WithIdentity(appName, instanceID string) ClientOption {
+	return func(c *Client) {
+		c.appName = appName
+		if instanceID != "" {
+			c.instanceID = instanceID
+		}
+	}
+}
+
+// WithHandshakeTimeout 设置等待服务端握手回复的时间
+func WithHandshakeTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		if timeout > 0 {
+			c.handshakeTimeout = timeout
+		}
 	}
+}
+
+// WithoutHandshake 不发送握手消息，连接直接使用协议版本1
+// 连接不支持握手的旧版本服务端时无需设置，客户端收到服务端的错误回复后会自动回退
+func WithoutHandshake() ClientOption {
+	return func(c *Client) {
+		c.handshake = false
+	}
+}
+
+// WithTLSConfig 使用TLS连接服务端，tlsConfig.ServerName为空时使用地址中的主机名校验服务端证书
+// 可以使用NewClientTLSConfig根据证书文件创建配置，配置客户端证书后可用于双向TLS认证
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig = tlsConfig
+	}
+}
 
+// WithDialer 使用自定义的方式建立连接，地址原样传给dialer，不再按传输方式解析
+func WithDialer(dialer transport.Dialer) ClientOption {
+	return func(c *Client) {
+		c.dialer = dialer
+	}
+}
+
+// WithClientConfig 使用配置文件中的客户端配置
+func WithClientConfig(cfg *config.ClientConfig) ClientOption {
+	return func(c *Client) {
+		if cfg == nil {
+			return
+		}
+		if cfg.KeepAlive {
+			WithKeepAlive(cfg.KeepAliveTime, cfg.KeepAliveCount)(c)
+		} else {
+			WithKeepAlive(0, 0)(c)
+		}
+		WithMessageSizeLimit(cfg.MaxHeaderBytes, cfg.MaxResponseSize)(c)
+		if cfg.CommonConfig != nil {
+			c.serializationType = uint8(cfg.SerializationType)
+			WithCompression(uint8(cfg.CompressType), cfg.CompressMinSize)(c)
+		}
+	}
+}
+
+func NewClient(address string, options ...ClientOption) (*Client, error) {
 	client := &Client{
-		conn:           NewConnection(conn),
-		enableFailover: false,
+		pending:           make(map[uint64]chan *protocol.Message),
+		streams:           make(map[uint64]*clientStream),
+		serializationType: protocol.SerializationTypeJSON,
+		keepAliveTime:     config.DefaultClientConfig.KeepAliveTime,
+		keepAliveCount:    config.DefaultClientConfig.KeepAliveCount,
+		maxHeaderBytes:    config.DefaultClientConfig.MaxHeaderBytes,
+		maxResponseSize:   config.DefaultClientConfig.MaxResponseSize,
+		handshake:         true,
+		handshakeTimeout:  defaultHandshakeTimeout,
+		instanceID:        defaultInstanceID(),
+		enableFailover:    false,
 	}
 
 	// 应用配置选项
 	for _, option := range options {
 		option(client)
 	}
+	// 故障转移探测实例时沿用客户端自定义的连接方式
+	if client.enableFailover && client.dialer != nil && client.failoverConfig != nil && client.failoverConfig.Dialer == nil {
+		cfg := *client.failoverConfig
+		cfg.Dialer = client.dialer
+		WithFailover(&cfg)(client)
+	}
+
+	client.interceptor = chainClientInterceptors(client.interceptors)
+
+	if protocol.GetCodecByType(client.serializationType) == nil {
+		return nil, NewRPCError(ErrCodeInvalidParam, "unsupported serialization type")
+	}
+	if client.compressType != protocol.CompressTypeNone && protocol.GetCompressorByType(client.compressType) == nil {
+		return nil, NewRPCError(ErrCodeInvalidParam, "unsupported compress type")
+	}
+	if client.credentials != nil && client.credentials.RequireTransportSecurity() && client.tlsConfig == nil {
+		return nil, NewRPCError(ErrCodeInvalidParam, "credentials require transport security")
+	}
+
+	conn, err := client.dial(address)
+	if err != nil {
+		return nil, WrapRPCError(ErrCodeUnavailable, "failed to connect", err)
+	}
+	client.conn = NewConnection(conn)
+	client.conn.SetCompression(client.compressType, client.compressMinSize)
+	// 握手协商协议版本和双方都支持的特性
+	if client.handshake {
+		client.conn.SetMaxMessageSize(handshakeLimit(client.maxHeaderBytes), handshakeLimit(client.maxResponseSize))
+		if err := client.negotiate(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	client.conn.SetMaxMessageSize(client.maxHeaderBytes, client.maxResponseSize)
+
+	// 启动读循环，将响应分发给对应的调用
+	go client.receive()
+	// 旧版本服务端不认识心跳消息，只在握手成功的连接上发送
+	if client.peer != nil {
+		go client.conn.keepAlive(client.keepAliveTime, client.keepAliveCount)
+	}
 
 	return client, nil
 }
 
-func (c *Client) Call(serviceName, methodName string, args interface{}) ([]byte, error) {
+// dial 建立到服务端的连接，配置了TLS时完成TLS握手后返回
+// 地址的传输方式决定连接类型，例如unix:///run/svc.sock使用Unix域套接字；建立连接和TLS握手最多等待handshakeTimeout
+func (c *Client) dial(address string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.handshakeTimeout)
+	defer cancel()
+
+	dialer := c.dialer
+	addr := address
+	if dialer == nil {
+		t, a, err := transport.Resolve(address)
+		if err != nil {
+			return nil, err
+		}
+		dialer, addr = t, a
+	}
+	conn, err := dialer.Dial(ctx, addr)
+	if err != nil || c.tlsConfig == nil {
+		return conn, err
+	}
+
+	// 未指定ServerName时使用地址中的主机名校验服务端证书
+	tlsConfig := c.tlsConfig
+	if tlsConfig.ServerName == "" && !tlsConfig.InsecureSkipVerify {
+		tlsConfig = tlsConfig.Clone()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			tlsConfig.ServerName = host
+		} else {
+			tlsConfig.ServerName = addr
+		}
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// negotiate 与服务端握手，并确认服务端支持客户端配置的序列化和压缩类型
+func (c *Client) negotiate() error {
+	local := protocol.NewHandshake()
+	local.MaxFrameSize = uint32(c.maxResponseSize)
+	local.AppName = c.appName
+	local.InstanceID = c.instanceID
+
+	peer, err := clientHandshake(c.conn, local, c.handshakeTimeout)
+	if err != nil {
+		return WrapRPCError(ErrCodeUnavailable, "handshake failed", err)
+	}
+	// 旧版本服务端不支持握手，按未握手的连接处理
+	if peer == nil {
+		return nil
+	}
+	if !peer.SupportsSerializer(c.serializationType) {
+		return NewRPCError(ErrCodeInvalidParam, fmt.Sprintf("serialization type %d is not supported by server", c.serializationType))
+	}
+	if !peer.SupportsCompressor(c.compressType) {
+		return NewRPCError(ErrCodeInvalidParam, fmt.Sprintf("compress type %d is not supported by server", c.compressType))
+	}
+	c.peer = peer
+	return nil
+}
+
+// Negotiated 返回与服务端握手协商的结果，包括协议版本、双方都支持的序列化和压缩类型以及服务端的消息大小限制
+// 未进行握手或服务端不支持握手时返回nil
+func (c *Client) Negotiated() *protocol.Handshake {
+	return c.peer
+}
+
+// checkRequest 根据握手结果检查请求能否被服务端接受
+func (c *Client) checkRequest(serializationType uint8, size int) error {
+	if c.peer == nil {
+		return nil
+	}
+	if !c.peer.SupportsSerializer(serializationType) {
+		return NewRPCError(ErrCodeInvalidParam, fmt.Sprintf("serialization type %d is not supported by server", serializationType))
+	}
+	if c.peer.MaxFrameSize > 0 && size > int(c.peer.MaxFrameSize) {
+		return NewRPCError(ErrCodeResourceExhausted, fmt.Sprintf("request too large: payload size %d exceeds server limit %d", size, c.peer.MaxFrameSize))
+	}
+	return nil
+}
+
+// CallOption 单次调用的配置选项
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	serializationType uint8  // 本次调用的序列化类型
+	version           string // 调用的服务版本，为空时使用服务端的默认版本
+}
+
+// WithCallSerialization 设置单次调用的序列化类型，覆盖客户端的默认配置
+func WithCallSerialization(serializationType uint8) CallOption {
+	return func(o *callOptions) {
+		o.serializationType = serializationType
+	}
+}
+
+// WithVersion 设置调用的服务版本，服务端将请求路由到该版本的服务
+func WithVersion(version string) CallOption {
+	return func(o *callOptions) {
+		o.version = version
+	}
+}
+
+// receive 读循环，每个连接只有一个读goroutine
+func (c *Client) receive() {
+	var err error
+	for {
+		var resp *protocol.Message
+		resp, err = c.conn.Read()
+		if err != nil {
+			// 超限的响应已被丢弃，只终止对应的调用
+			var tooLarge *protocol.FrameTooLargeError
+			if errors.As(err, &tooLarge) {
+				c.rejectResponse(tooLarge)
+				continue
+			}
+			break
+		}
+
+		// 服务端即将关闭，停止发起新调用，已发出的调用继续等待响应
+		if resp.Header.MessageType == protocol.TypeGoAway {
+			c.mu.Lock()
+			c.draining = true
+			c.mu.Unlock()
+			continue
+		}
+
+		// 心跳响应只用于刷新连接的活跃时间
+		switch resp.Header.MessageType {
+		case protocol.TypePing:
+			c.conn.pong(resp.Header.MessageID)
+			continue
+		case protocol.TypePong:
+			continue
+		}
+
+		// 流消息投递到对应流的接收队列
+		if resp.Header.MessageType == protocol.TypeStreamData || resp.Header.MessageType == protocol.TypeStreamClose {
+			c.dispatchStream(resp)
+			continue
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[resp.Header.MessageID]
+		delete(c.pending, resp.Header.MessageID)
+		c.mu.Unlock()
+
+		// 调用方已经放弃（例如超时），丢弃该响应
+		if !ok {
+			continue
+		}
+		ch <- resp
+	}
+
+	// 连接出错，终止所有等待中的调用
+	c.mu.Lock()
+	c.shutdown = true
+	if !c.closing {
+		utils.Debug("Client connection terminated: %v", err)
+	}
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+	streams := c.streams
+	c.streams = make(map[uint64]*clientStream)
+	c.mu.Unlock()
+
+	for _, cs := range streams {
+		cs.fail(NewRPCError(ErrCodeUnavailable, "stream terminated: connection closed"))
+	}
+}
+
+// rejectResponse 以资源耗尽错误终止接收到超限响应的调用或流
+func (c *Client) rejectResponse(tooLarge *protocol.FrameTooLargeError) {
+	status := toStatus(NewRPCError(ErrCodeResourceExhausted, "response too large: "+tooLarge.Error()))
+	msg := &protocol.Message{
+		Header:   tooLarge.Header,
+		Metadata: &protocol.Metadata{Error: status.Message, Status: status},
+	}
+
+	switch tooLarge.Header.MessageType {
+	case protocol.TypeResponse:
+		c.mu.Lock()
+		ch, ok := c.pending[msg.Header.MessageID]
+		delete(c.pending, msg.Header.MessageID)
+		c.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	case protocol.TypeStreamData, protocol.TypeStreamClose:
+		c.mu.Lock()
+		cs, ok := c.streams[msg.Header.MessageID]
+		c.mu.Unlock()
+		if ok {
+			cs.abort(fromMetadata(msg.Metadata))
+		}
+	default:
+		utils.Warn("Dropping oversized message of type %d: %v", tooLarge.Header.MessageType, tooLarge)
+	}
+}
+
+// dispatchStream 将流消息投递到对应的流，结束消息同时移除该流
+func (c *Client) dispatchStream(msg *protocol.Message) {
+	c.mu.Lock()
+	cs, ok := c.streams[msg.Header.MessageID]
+	if ok && msg.Header.MessageType == protocol.TypeStreamClose {
+		delete(c.streams, msg.Header.MessageID)
+	}
+	c.mu.Unlock()
+
+	// 流已被调用方放弃，丢弃该消息
+	if !ok {
+		return
+	}
+	if msg.Header.MessageType != protocol.TypeStreamClose {
+		cs.buf.put(msg, c.conn.closed)
+		return
+	}
+	// 服务端已结束流，调用方不再读取时也要结束流，释放监听上下文的协程
+	status := streamStatus(msg)
+	cs.buf.put(msg, c.conn.closed)
+	cs.finish(status)
+}
+
+// removeStream 移除进行中的流
+func (c *Client) removeStream(messageID uint64) {
+	c.mu.Lock()
+	delete(c.streams, messageID)
+	c.mu.Unlock()
+}
+
+// available 判断连接是否仍可发起新调用
+func (c *Client) available() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.closing && !c.shutdown && !c.draining
+}
+
+// register 登记一个等待响应的调用
+func (c *Client) register(messageID uint64) (chan *protocol.Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closing || c.shutdown {
+		return nil, ErrShutdown
+	}
+	if c.draining {
+		return nil, ErrDraining
+	}
+
+	ch := make(chan *protocol.Message, 1)
+	c.pending[messageID] = ch
+	return ch, nil
+}
+
+// unregister 移除等待中的调用
+func (c *Client) unregister(messageID uint64) {
+	c.mu.Lock()
+	delete(c.pending, messageID)
+	c.mu.Unlock()
+}
+
+// Call 调用远程方法，返回未解码的响应数据
+func (c *Client) Call(serviceName, methodName string, args interface{}, opts ...CallOption) ([]byte, error) {
+	return c.CallWithTimeout(context.Background(), serviceName, methodName, args, opts...)
+}
+
+// CallWithTimeout 带超时的RPC调用
+func (c *Client) CallWithTimeout(ctx context.Context, serviceName, methodName string, args interface{}, opts ...CallOption) ([]byte, error) {
+	var data []byte
+	if err := c.Invoke(ctx, serviceName, methodName, args, &data, opts...); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Invoke 调用远程方法并将响应解码到reply，响应使用与请求相同的序列化类型
+// reply为*[]byte时直接写入未解码的响应数据；配置了拦截器时经由拦截器链调用
+func (c *Client) Invoke(ctx context.Context, serviceName, methodName string, args, reply interface{}, opts ...CallOption) error {
+	if c.interceptor != nil {
+		return c.interceptor(ctx, serviceName, methodName, args, reply, c.unaryInvoke, opts...)
+	}
+	return c.unaryInvoke(ctx, serviceName, methodName, args, reply, opts...)
+}
+
+// unaryInvoke 拦截器链末端的实际调用
+func (c *Client) unaryInvoke(ctx context.Context, serviceName, methodName string, args, reply interface{}, opts ...CallOption) error {
+	resp, err := c.invoke(ctx, serviceName, methodName, args, opts)
+	if err != nil {
+		return err
+	}
+	// 响应数据复制或解码后不再被引用，归还缓冲区
+	defer resp.Release()
+
+	// 复制原始响应数据，缓冲区按容量分级，直接返回会让调用方长期持有整个缓冲区
+	if raw, ok := reply.(*[]byte); ok {
+		*raw = append([]byte(nil), resp.Payload...)
+		return nil
+	}
+
+	// 只返回error的方法响应为空，调用方不关心响应时也无需解码
+	if reply == nil || len(resp.Payload) == 0 {
+		return nil
+	}
+
+	serializer := protocol.GetCodecByType(resp.Header.SerializationType)
+	if serializer == nil {
+		return NewRPCError(ErrCodeInvalidParam, "unsupported serialization type")
+	}
+	if err := serializer.Decode(resp.Payload, reply); err != nil {
+		return WrapRPCError(ErrCodeInternal, "failed to decode response", err)
+	}
+	return nil
+}
+
+// invoke 发送请求并等待对应MessageID的响应
+func (c *Client) invoke(ctx context.Context, serviceName, methodName string, args interface{}, opts []CallOption) (*protocol.Message, error) {
+	callOpts := &callOptions{
+		serializationType: c.serializationType,
+	}
+	for _, opt := range opts {
+		opt(callOpts)
+	}
+
 	// 序列化请求参数
-	serializer := codec.GetCodec(codec.JSON) // 默认使用JSON
+	serializer := protocol.GetCodecByType(callOpts.serializationType)
+	if serializer == nil {
+		return nil, NewRPCError(ErrCodeInvalidParam, "unsupported serialization type")
+	}
 	argBytes, err := serializer.Encode(args)
 	if err != nil {
-		return nil, NewRPCError(ErrCodeInvalidParam, "failed to marshal request: "+err.Error())
+		return nil, WrapRPCError(ErrCodeInvalidParam, "failed to marshal request", err)
+	}
+	if err := c.checkRequest(callOpts.serializationType, len(argBytes)); err != nil {
+		return nil, err
 	}
 
-	// 构造元数据
-	metadata := &protocol.Metadata{
-		ServiceName: serviceName,
-		MethodName:  methodName,
+	// 调用方已经放弃时无需再发送请求
+	if ctx.Err() != nil {
+		return nil, contextError(ctx.Err())
+	}
+
+	metadata, err := c.requestMetadata(ctx, serviceName, methodName, callOpts.version, argBytes)
+	if err != nil {
+		return nil, err
 	}
 
-	// 生成消息ID
+	// 生成消息ID并登记调用
 	messageID := atomic.AddUint64(&c.messageID, 1)
+	respCh, err := c.register(messageID)
+	if err != nil {
+		return nil, err
+	}
 
 	// 发送请求
 	err = c.conn.Write(
 		serviceName,
 		methodName,
 		protocol.TypeRequest,
-		protocol.SerializationTypeJSON,
+		callOpts.serializationType,
 		messageID,
 		metadata,
 		argBytes,
 	)
 	if err != nil {
-		return nil, NewRPCError(ErrCodeInternal, "failed to send request: "+err.Error())
+		c.unregister(messageID)
+		return nil, WrapRPCError(ErrCodeUnavailable, "failed to send request", err)
+	}
+
+	// 等待响应
+	var resp *protocol.Message
+	select {
+	case <-ctx.Done():
+		c.unregister(messageID)
+		c.cancel(messageID)
+		return nil, contextError(ctx.Err())
+	case msg, ok := <-respCh:
+		if !ok {
+			return nil, NewRPCError(ErrCodeUnavailable, "failed to receive response: connection closed")
+		}
+		resp = msg
+	}
+
+	// 检查响应中的错误，还原服务端返回的错误码和详情
+	if err := fromMetadata(resp.Metadata); err != nil {
+		resp.Release()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Notify 发起单向调用，请求写入连接后立即返回，不等待服务端处理
+// 服务端不发送响应，处理失败时调用方无法感知；配置了拦截器时经由拦截器链调用，reply为nil
+func (c *Client) Notify(ctx context.Context, serviceName, methodName string, args interface{}, opts ...CallOption) error {
+	if c.interceptor != nil {
+		return c.interceptor(ctx, serviceName, methodName, args, nil, c.onewayInvoke, opts...)
+	}
+	return c.onewayInvoke(ctx, serviceName, methodName, args, nil, opts...)
+}
+
+// onewayInvoke 单向调用拦截器链末端的实际调用，忽略reply
+func (c *Client) onewayInvoke(ctx context.Context, serviceName, methodName string, args, _ interface{}, opts ...CallOption) error {
+	callOpts := &callOptions{
+		serializationType: c.serializationType,
+	}
+	for _, opt := range opts {
+		opt(callOpts)
+	}
+
+	serializer := protocol.GetCodecByType(callOpts.serializationType)
+	if serializer == nil {
+		return NewRPCError(ErrCodeInvalidParam, "unsupported serialization type")
+	}
+	argBytes, err := serializer.Encode(args)
+	if err != nil {
+		return WrapRPCError(ErrCodeInvalidParam, "failed to marshal request", err)
+	}
+	if err := c.checkRequest(callOpts.serializationType, len(argBytes)); err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return contextError(ctx.Err())
+	}
+
+	c.mu.Lock()
+	if c.closing || c.shutdown {
+		c.mu.Unlock()
+		return ErrShutdown
+	}
+	if c.draining {
+		c.mu.Unlock()
+		return ErrDraining
 	}
+	c.mu.Unlock()
 
-	// 接收响应
-	resp, err := c.conn.Read()
+	metadata, err := c.requestMetadata(ctx, serviceName, methodName, callOpts.version, argBytes)
 	if err != nil {
-		return nil, NewRPCError(ErrCodeInternal, "failed to receive response: "+err.Error())
+		return err
+	}
+	messageID := atomic.AddUint64(&c.messageID, 1)
+	err = c.conn.Write(serviceName, methodName, protocol.TypeOneway, callOpts.serializationType, messageID, metadata, argBytes)
+	if err != nil {
+		return WrapRPCError(ErrCodeUnavailable, "failed to send request", err)
 	}
+	return nil
+}
 
-	// 检查响应中的错误
-	if resp.Metadata != nil && resp.Metadata.Error != "" {
-		return nil, NewRPCError(ErrCodeInternal, resp.Metadata.Error)
+// NewStream 打开一个流式调用，流的序列化类型由客户端配置和opts决定
+// ctx结束时流被取消，服务端的处理也随之结束；连接未完成握手时(如服务端为旧版本)不支持流式调用
+func (c *Client) NewStream(ctx context.Context, serviceName, methodName string, opts ...CallOption) (ClientStream, error) {
+	callOpts := &callOptions{
+		serializationType: c.serializationType,
+	}
+	for _, opt := range opts {
+		opt(callOpts)
+	}
+	if protocol.GetCodecByType(callOpts.serializationType) == nil {
+		return nil, NewRPCError(ErrCodeInvalidParam, "unsupported serialization type")
+	}
+	if err := c.checkRequest(callOpts.serializationType, 0); err != nil {
+		return nil, err
+	}
+	// 流消息不携带元数据，旧版本服务端无法处理
+	if c.peer == nil {
+		return nil, NewRPCError(ErrCodeInvalidParam, "streams are not supported without a negotiated handshake")
+	}
+	if ctx.Err() != nil {
+		return nil, contextError(ctx.Err())
+	}
+
+	cs := &clientStream{
+		ctx:               ctx,
+		client:            c,
+		messageID:         atomic.AddUint64(&c.messageID, 1),
+		serializationType: callOpts.serializationType,
+		buf:               newStreamBuffer(),
+		done:              make(chan struct{}),
+	}
+
+	c.mu.Lock()
+	if c.closing || c.shutdown {
+		c.mu.Unlock()
+		return nil, ErrShutdown
+	}
+	if c.draining {
+		c.mu.Unlock()
+		return nil, ErrDraining
+	}
+	c.streams[cs.messageID] = cs
+	c.mu.Unlock()
+
+	metadata, err := c.requestMetadata(ctx, serviceName, methodName, callOpts.version, nil)
+	if err != nil {
+		c.removeStream(cs.messageID)
+		return nil, err
+	}
+	err = c.conn.Write(serviceName, methodName, protocol.TypeStreamOpen, cs.serializationType, cs.messageID, metadata, nil)
+	if err != nil {
+		c.removeStream(cs.messageID)
+		return nil, WrapRPCError(ErrCodeUnavailable, "failed to open stream", err)
 	}
 
-	return resp.Payload, nil
+	go cs.watch()
+	return cs, nil
 }
 
-// CallWithTimeout 带超时的RPC调用
-func (c *Client) CallWithTimeout(ctx context.Context, serviceName, methodName string, args interface{}) ([]byte, error) {
-	done := make(chan struct{})
-	var result []byte
-	var err error
+// requestMetadata 构造请求元数据，携带上下文中的请求元数据、认证信息和截止时间
+// payload为序列化后的请求体，供需要对请求体签名的认证信息使用
+func (c *Client) requestMetadata(ctx context.Context, serviceName, methodName, version string, payload []byte) (*protocol.Metadata, error) {
+	metadata := &protocol.Metadata{
+		ServiceName: serviceName,
+		MethodName:  methodName,
+		Version:     version,
+	}
+	if md := OutgoingMetadata(ctx); len(md) > 0 {
+		metadata.Extra = make(map[string]string, len(md))
+		for k, v := range md {
+			metadata.Extra[k] = v
+		}
+	}
 
-	go func() {
-		result, err = c.Call(serviceName, methodName, args)
-		close(done)
-	}()
+	if c.credentials != nil {
+		var authMD map[string]string
+		var err error
+		if creds, ok := c.credentials.(requestCredentials); ok {
+			authMD, err = creds.getRequestMetadata(ctx, metadata, payload)
+		} else {
+			authMD, err = c.credentials.GetRequestMetadata(ctx, serviceName, methodName)
+		}
+		if err != nil {
+			return nil, WrapRPCError(ErrCodeUnauthenticated, "failed to get request credentials", err)
+		}
+		if len(authMD) > 0 && metadata.Extra == nil {
+			metadata.Extra = make(map[string]string, len(authMD))
+		}
+		for k, v := range authMD {
+			metadata.Extra[k] = v
+		}
+	}
 
-	select {
-	case <-ctx.Done():
-		// 不再关闭连接，而是让连接池处理
-		return nil, NewRPCError(ErrCodeInternal, "request timeout")
-	case <-done:
-		return result, err
+	// 将截止时间传递给服务端
+	if deadline, ok := ctx.Deadline(); ok {
+		metadata.Timeout = timeoutMillis(time.Until(deadline))
+	}
+	return metadata, nil
+}
+
+// cancel 通知服务端放弃指定请求，发送失败时忽略
+func (c *Client) cancel(messageID uint64) {
+	// 旧版本服务端不识别取消消息，未握手的连接上不发送
+	if c.peer == nil {
+		return
+	}
+	err := c.conn.Write("", "", protocol.TypeCancel, protocol.SerializationTypeJSON, messageID, nil, nil)
+	if err != nil {
+		utils.Debug("Failed to send cancel for message %d: %v", messageID, err)
 	}
 }
 
+// timeoutMillis 将剩余时间换算为毫秒，不足1毫秒按1毫秒计算
+func timeoutMillis(d time.Duration) int64 {
+	if d <= 0 {
+		return 1
+	}
+	ms := int64(d / time.Millisecond)
+	if d%time.Millisecond != 0 {
+		ms++
+	}
+	return ms
+}
+
+// contextError 将context错误转换为RPC错误
+func contextError(err error) *RPCError {
+	if errors.Is(err, context.Canceled) {
+		return NewRPCError(ErrCodeCanceled, "request canceled")
+	}
+	return NewRPCError(ErrCodeTimeout, "request timeout")
+}
+
 // CallWithFailover 带故障转移功能的RPC调用
 func (c *Client) CallWithFailover(ctx context.Context, serviceName, methodName string, args interface{}, instances []*naming.Instance) ([]byte, error) {
 	if !c.enableFailover || c.failoverHandler == nil || len(instances) == 0 {
@@ -127,7 +825,7 @@ func (c *Client) CallWithFailover(ctx context.Context, serviceName, methodName s
 	// 封装RPC调用操作
 	operation := func(ctx context.Context, instance *naming.Instance) error {
 		// 创建到具体实例的新连接
-		client, err := NewClient(instance.Address)
+		client, err := NewClient(instance.Address, c.instanceOptions()...)
 		if err != nil {
 			return err
 		}
@@ -141,21 +839,47 @@ func (c *Client) CallWithFailover(ctx context.Context, serviceName, methodName s
 	// 执行带故障转移的调用
 	result, err := c.failoverHandler.Execute(ctx, instances, operation)
 	if err != nil {
-		return nil, NewRPCError(ErrCodeInternal, "failover failed: "+err.Error())
+		return nil, WrapRPCError(ErrCodeInternal, "failover failed", err)
 	}
 
 	// 如果故障转移成功，通过成功的实例再执行一次调用返回结果
 	if result.Success {
-		client, err := NewClient(result.Instance.Address)
+		client, err := NewClient(result.Instance.Address, c.instanceOptions()...)
 		if err != nil {
-			return nil, NewRPCError(ErrCodeInternal, "failed to connect to selected instance: "+err.Error())
+			return nil, WrapRPCError(ErrCodeUnavailable, "failed to connect to selected instance", err)
 		}
 		defer client.Close()
 
 		return client.CallWithTimeout(ctx, serviceName, methodName, args)
 	}
 
-	return nil, NewRPCError(ErrCodeInternal, "no available instances after failover attempts")
+	return nil, NewRPCError(ErrCodeUnavailable, "no available instances after failover attempts")
+}
+
+// instanceOptions 返回连接其他实例时沿用的客户端配置
+func (c *Client) instanceOptions() []ClientOption {
+	opts := []ClientOption{
+		WithSerialization(c.serializationType),
+		WithCompression(c.compressType, c.compressMinSize),
+		WithKeepAlive(c.keepAliveTime, c.keepAliveCount),
+		WithMessageSizeLimit(c.maxHeaderBytes, c.maxResponseSize),
+		WithIdentity(c.appName, c.instanceID),
+		WithHandshakeTimeout(c.handshakeTimeout),
+		WithInterceptors(c.interceptors...),
+	}
+	if !c.handshake {
+		opts = append(opts, WithoutHandshake())
+	}
+	if c.tlsConfig != nil {
+		opts = append(opts, WithTLSConfig(c.tlsConfig))
+	}
+	if c.credentials != nil {
+		opts = append(opts, WithPerRPCCredentials(c.credentials))
+	}
+	if c.dialer != nil {
+		opts = append(opts, WithDialer(c.dialer))
+	}
+	return opts
 }
 
 // IsFailoverEnabled 检查是否启用了故障转移功能
@@ -169,5 +893,13 @@ func (c *Client) GetFailoverHandler() *failover.DefaultFailoverHandler {
 }
 
 func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		return ErrShutdown
+	}
+	c.closing = true
+	c.mu.Unlock()
+
 	return c.conn.Close()
 }
@@ -94,12 +94,34 @@ func (s *mockServer) handleConnection(b *testing.B, conn net.Conn) {
 				return
 			}
 
+			// 回复本端握手信息
+			if msg.Header.MessageType == protocol.TypeHandshake {
+				data, err := jsonCodec.Encode(protocol.NewHandshake())
+				if err != nil {
+					return
+				}
+				if err := proto.EncodeMessage(&protocol.Message{
+					Header: protocol.Header{
+						MagicNumber:       protocol.MagicNumber,
+						Version:           protocol.MinProtocolVersion,
+						MessageType:       protocol.TypeHandshake,
+						SerializationType: protocol.SerializationTypeJSON,
+					},
+					Payload: data,
+				}, conn); err != nil {
+					return
+				}
+				continue
+			}
+
 			if msg.Header.MessageType != protocol.TypeRequest {
 				continue
 			}
 
 			req := &benchmarkRequest{}
-			if err := jsonCodec.Decode(msg.Payload, req); err != nil {
+			err = jsonCodec.Decode(msg.Payload, req)
+			msg.Release()
+			if err != nil {
 				continue
 			}
 
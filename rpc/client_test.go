@@ -0,0 +1,131 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/fyerfyer/fyer-rpc/protocol/codec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestServer 在随机端口上启动服务器并返回地址
+func startTestServer(t *testing.T, server *Server) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	go func() {
+		_ = server.Start(addr)
+	}()
+
+	// 等待服务器开始监听
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	return addr
+}
+
+func TestClientConcurrentCalls(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.RegisterService(&BenchService{}))
+	addr := startTestServer(t, server)
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	jsonCodec := codec.GetCodec(codec.JSON)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			value := fmt.Sprintf("request-%d", i)
+			data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: value})
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			resp := &BenchResponse{}
+			require.NoError(t, jsonCodec.Decode(data, resp))
+			assert.Equal(t, value, resp.Value)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestClientCallTimeoutKeepsConnectionUsable(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.RegisterService(&BenchService{}))
+	addr := startTestServer(t, server)
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	time.Sleep(2 * time.Millisecond)
+	_, err = client.CallWithTimeout(ctx, "BenchService", "Echo", &BenchRequest{Value: "expired"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout")
+
+	// 超时的调用不应影响后续调用
+	data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "next"})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "next")
+}
+
+func TestClientCallAfterClose(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.RegisterService(&BenchService{}))
+	addr := startTestServer(t, server)
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	require.NoError(t, client.Close())
+
+	_, err = client.Call("BenchService", "Echo", &BenchRequest{Value: "closed"})
+	assert.ErrorIs(t, err, ErrShutdown)
+}
+
+func TestClientCompression(t *testing.T) {
+	server := NewServer(WithServerCompression(protocol.CompressTypeGzip, 1024))
+	require.NoError(t, server.RegisterService(&BenchService{}))
+	addr := startTestServer(t, server)
+
+	compressTypes := []uint8{protocol.CompressTypeGzip, protocol.CompressTypeDeflate, protocol.CompressTypeZlib}
+	for _, compressType := range compressTypes {
+		client, err := NewClient(addr, WithCompression(compressType, 1024))
+		require.NoError(t, err)
+
+		for _, value := range []string{"small", strings.Repeat("large payload ", 1000)} {
+			data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: value})
+			require.NoError(t, err)
+
+			resp := &BenchResponse{}
+			require.NoError(t, codec.GetCodec(codec.JSON).Decode(data, resp))
+			assert.Equal(t, value, resp.Value)
+		}
+		client.Close()
+	}
+
+	_, err := NewClient(addr, WithCompression(0x7f, 0))
+	assert.Error(t, err)
+}
@@ -1,31 +1,77 @@
 package rpc
 
 import (
+	"bufio"
 	"net"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/fyerfyer/fyer-rpc/protocol"
 )
 
 // Connection 包装底层连接，提供协议层面的读写能力
+// Write 可以被多个goroutine并发调用，Read 只应由单个读goroutine调用
 type Connection struct {
-	conn     net.Conn
-	protocol protocol.Protocol
+	conn            net.Conn
+	reader          *bufio.Reader            // 带缓冲的读取端，减少读取小消息时的系统调用
+	encoder         protocol.DefaultProtocol // 编码发送的消息，创建后不再修改
+	decoder         protocol.DefaultProtocol // 解码读取的消息，带大小限制，只由读goroutine访问
+	writeMu         sync.Mutex               // 保证一帧消息完整写出，避免并发写入交错
+	compressType    uint8                    // 发送消息使用的压缩类型
+	compressMinSize int                      // 消息体达到该大小才压缩
+	version         uint8                    // 发送消息使用的协议版本，握手完成前为最低版本
+	lastRead        int64                    // 最近一次读到消息的时间(UnixNano)，用于心跳检测
+	closeOnce       sync.Once
+	closed          chan struct{} // 连接关闭时关闭
 }
 
+// readBufferSize 连接读缓冲区大小
+const readBufferSize = 32 << 10
+
 func NewConnection(conn net.Conn) *Connection {
 	return &Connection{
 		conn:     conn,
-		protocol: &protocol.DefaultProtocol{},
+		reader:   bufio.NewReaderSize(conn, readBufferSize),
+		version:  protocol.MinProtocolVersion,
+		lastRead: time.Now().UnixNano(),
+		closed:   make(chan struct{}),
 	}
 }
 
+// SetMaxMessageSize 设置读取消息时接受的最大元数据和消息体大小，为0时不限制
+// 超限的消息会被丢弃，Read返回*protocol.FrameTooLargeError
+// 限制只作用于解码端，与并发的Write不共享状态；应在读goroutine中或启动读循环之前调用
+func (c *Connection) SetMaxMessageSize(maxMetadataSize, maxPayloadSize int) {
+	c.decoder.MaxMetadataSize = uint32(maxMetadataSize)
+	c.decoder.MaxPayloadSize = uint32(maxPayloadSize)
+}
+
+// SetVersion 设置发送消息使用的协议版本，应在握手完成、开始收发其他消息前调用
+func (c *Connection) SetVersion(version uint8) {
+	c.writeMu.Lock()
+	c.version = version
+	c.writeMu.Unlock()
+}
+
+// SetCompression 设置发送消息时使用的压缩类型和启用压缩的最小消息体大小
+func (c *Connection) SetCompression(compressType uint8, minSize int) {
+	c.compressType = compressType
+	c.compressMinSize = minSize
+}
+
 func (c *Connection) Write(serviceName, methodName string, messageType uint8, serializationType uint8, messageID uint64, metadata *protocol.Metadata, payload []byte) error {
+	// 消息体过小时压缩收益不明显，直接发送
+	compressType := protocol.CompressTypeNone
+	if len(payload) > 0 && len(payload) >= c.compressMinSize {
+		compressType = c.compressType
+	}
+
 	message := &protocol.Message{
 		Header: protocol.Header{
 			MagicNumber:       protocol.MagicNumber,
-			Version:           1,
 			MessageType:       messageType,
-			CompressType:      protocol.CompressTypeNone, // 暂时不启用压缩
+			CompressType:      compressType,
 			SerializationType: serializationType,
 			MessageID:         messageID,
 		},
@@ -33,13 +79,22 @@ func (c *Connection) Write(serviceName, methodName string, messageType uint8, se
 		Payload:  payload,
 	}
 
-	return c.protocol.EncodeMessage(message, c.conn)
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	message.Header.Version = c.version
+	return c.encoder.EncodeMessage(message, c.conn)
 }
 
 func (c *Connection) Read() (*protocol.Message, error) {
-	return c.protocol.DecodeMessage(c.conn)
+	message, err := c.decoder.DecodeMessage(c.reader)
+	if err == nil {
+		atomic.StoreInt64(&c.lastRead, time.Now().UnixNano())
+	}
+	return message, err
 }
 
 func (c *Connection) Close() error {
-	return c.conn.Close()
+	err := c.conn.Close()
+	c.closeOnce.Do(func() { close(c.closed) })
+	return err
 }
@@ -0,0 +1,297 @@
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+)
+
+// 认证信息使用的请求元数据键
+const (
+	AuthorizationKey = "authorization"    // Bearer令牌
+	AuthKeyIDKey     = "x-auth-key-id"    // HMAC密钥ID
+	AuthTimestampKey = "x-auth-timestamp" // HMAC签名时间(Unix毫秒)
+	AuthNonceKey     = "x-auth-nonce"     // HMAC随机数，用于防重放
+	AuthSignatureKey = "x-auth-signature" // HMAC签名
+	bearerPrefix     = "Bearer "
+	defaultClockSkew = 5 * time.Minute
+)
+
+// PerRPCCredentials 客户端每次调用时附加的认证信息
+type PerRPCCredentials interface {
+	// GetRequestMetadata 返回需要写入请求Metadata.Extra的认证信息
+	GetRequestMetadata(ctx context.Context, serviceName, methodName string) (map[string]string, error)
+	// RequireTransportSecurity 是否只能在TLS连接上发送
+	RequireTransportSecurity() bool
+}
+
+// requestCredentials 需要对请求版本和请求体签名的认证信息，客户端优先使用
+type requestCredentials interface {
+	getRequestMetadata(ctx context.Context, metadata *protocol.Metadata, payload []byte) (map[string]string, error)
+}
+
+// Authenticator 服务端认证器，在分发请求前校验请求元数据中的认证信息
+// 认证失败返回错误，非RPCError的错误按ErrCodeUnauthenticated返回给客户端；
+// 认证成功时可以返回附加了AuthInfo的上下文供处理器使用
+type Authenticator interface {
+	Authenticate(ctx context.Context, info *ServerInfo) (context.Context, error)
+}
+
+// AuthInfo 认证通过的调用方信息
+type AuthInfo struct {
+	Scheme  string // 认证方式，如"bearer"、"hmac"
+	Subject string // 调用方身份
+}
+
+type authInfoKey struct{}
+
+// WithAuthInfo 将认证信息附加到上下文，供自定义Authenticator使用
+func WithAuthInfo(ctx context.Context, info *AuthInfo) context.Context {
+	return context.WithValue(ctx, authInfoKey{}, info)
+}
+
+// AuthInfoFromContext 在服务端获取认证通过的调用方信息
+func AuthInfoFromContext(ctx context.Context) (*AuthInfo, bool) {
+	info, ok := ctx.Value(authInfoKey{}).(*AuthInfo)
+	return info, ok
+}
+
+// WithPerRPCCredentials 设置每次调用附加的认证信息
+func WithPerRPCCredentials(creds PerRPCCredentials) ClientOption {
+	return func(c *Client) {
+		c.credentials = creds
+	}
+}
+
+// WithAuthenticator 设置服务端认证器，一元调用和流都需要通过认证
+func WithAuthenticator(authenticator Authenticator) ServerOption {
+	return func(s *Server) {
+		s.authenticator = authenticator
+	}
+}
+
+// authenticate 使用认证器校验请求，未设置认证器时直接通过
+func (s *Server) authenticate(ctx context.Context, metadata *protocol.Metadata, payload []byte) (context.Context, error) {
+	if s.authenticator == nil {
+		return ctx, nil
+	}
+	info := &ServerInfo{
+		ServiceName: metadata.ServiceName,
+		MethodName:  metadata.MethodName,
+		Metadata:    metadata,
+		Payload:     payload,
+	}
+	authCtx, err := s.authenticator.Authenticate(ctx, info)
+	if err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			err = WrapRPCError(ErrCodeUnauthenticated, "unauthenticated", err)
+		}
+		return nil, err
+	}
+	if authCtx == nil {
+		authCtx = ctx
+	}
+	return authCtx, nil
+}
+
+// tokenCredentials 静态Bearer令牌
+type tokenCredentials struct {
+	token string
+}
+
+// NewTokenCredentials 创建在每次调用时携带静态Bearer令牌的认证信息
+// 令牌以明文传输，生产环境应配合TLS使用
+func NewTokenCredentials(token string) PerRPCCredentials {
+	return &tokenCredentials{token: token}
+}
+
+func (c *tokenCredentials) GetRequestMetadata(ctx context.Context, serviceName, methodName string) (map[string]string, error) {
+	return map[string]string{AuthorizationKey: bearerPrefix + c.token}, nil
+}
+
+func (c *tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// tokenAuthenticator 静态令牌认证器
+type tokenAuthenticator struct {
+	tokens map[string]string // 令牌 -> 调用方身份
+}
+
+// NewTokenAuthenticator 创建静态令牌认证器，tokens为令牌到调用方身份的映射
+func NewTokenAuthenticator(tokens map[string]string) Authenticator {
+	copied := make(map[string]string, len(tokens))
+	for token, subject := range tokens {
+		copied[token] = subject
+	}
+	return &tokenAuthenticator{tokens: copied}
+}
+
+func (a *tokenAuthenticator) Authenticate(ctx context.Context, info *ServerInfo) (context.Context, error) {
+	value := info.Metadata.Extra[AuthorizationKey]
+	if !strings.HasPrefix(value, bearerPrefix) {
+		return nil, NewRPCError(ErrCodeUnauthenticated, "missing bearer token")
+	}
+	token := value[len(bearerPrefix):]
+
+	// 逐个比较所有令牌，避免通过耗时推测令牌内容
+	subject, found := "", false
+	for t, s := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			subject, found = s, true
+		}
+	}
+	if !found {
+		return nil, NewRPCError(ErrCodeUnauthenticated, "invalid bearer token")
+	}
+	return WithAuthInfo(ctx, &AuthInfo{Scheme: "bearer", Subject: subject}), nil
+}
+
+// hmacCredentials 基于共享密钥的HMAC签名
+type hmacCredentials struct {
+	keyID  string
+	secret []byte
+}
+
+// NewHMACCredentials 创建使用共享密钥签名的认证信息
+// 签名覆盖密钥ID、服务名、方法名、服务版本、时间戳、随机数和请求体的SHA-256摘要
+func NewHMACCredentials(keyID string, secret []byte) PerRPCCredentials {
+	return &hmacCredentials{keyID: keyID, secret: secret}
+}
+
+func (c *hmacCredentials) GetRequestMetadata(ctx context.Context, serviceName, methodName string) (map[string]string, error) {
+	return c.getRequestMetadata(ctx, &protocol.Metadata{ServiceName: serviceName, MethodName: methodName}, nil)
+}
+
+func (c *hmacCredentials) getRequestMetadata(ctx context.Context, metadata *protocol.Metadata, payload []byte) (map[string]string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	nonceHex := hex.EncodeToString(nonce)
+
+	return map[string]string{
+		AuthKeyIDKey:     c.keyID,
+		AuthTimestampKey: timestamp,
+		AuthNonceKey:     nonceHex,
+		AuthSignatureKey: signHMAC(c.secret, c.keyID, metadata.ServiceName, metadata.MethodName, metadata.Version, timestamp, nonceHex, payload),
+	}, nil
+}
+
+func (c *hmacCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// signHMAC 计算HMAC-SHA256签名，请求体以SHA-256摘要参与签名
+func signHMAC(secret []byte, keyID, serviceName, methodName, version, timestamp, nonce string, payload []byte) string {
+	digest := sha256.Sum256(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join([]string{keyID, serviceName, methodName, version, timestamp, nonce, hex.EncodeToString(digest[:])}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HMACOption HMAC认证器配置选项
+type HMACOption func(*hmacAuthenticator)
+
+// WithMaxClockSkew 设置允许的签名时间与服务端时间的最大偏差，默认5分钟
+func WithMaxClockSkew(skew time.Duration) HMACOption {
+	return func(a *hmacAuthenticator) {
+		if skew > 0 {
+			a.maxSkew = skew
+		}
+	}
+}
+
+// hmacAuthenticator HMAC签名认证器
+// 只接受时间戳在maxSkew内的请求，并记录该时间窗口内出现过的随机数以拒绝重放的请求
+type hmacAuthenticator struct {
+	secrets map[string][]byte // 密钥ID -> 共享密钥
+	maxSkew time.Duration
+
+	mu     sync.Mutex
+	nonces map[string]time.Time // 已使用的随机数 -> 过期时间
+	sweep  time.Time            // 下次清理过期随机数的时间
+}
+
+// NewHMACAuthenticator 创建HMAC签名认证器，secrets为密钥ID到共享密钥的映射，认证通过后密钥ID作为调用方身份
+func NewHMACAuthenticator(secrets map[string][]byte, opts ...HMACOption) Authenticator {
+	a := &hmacAuthenticator{
+		secrets: make(map[string][]byte, len(secrets)),
+		maxSkew: defaultClockSkew,
+		nonces:  make(map[string]time.Time),
+	}
+	for keyID, secret := range secrets {
+		a.secrets[keyID] = secret
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+func (a *hmacAuthenticator) Authenticate(ctx context.Context, info *ServerInfo) (context.Context, error) {
+	extra := info.Metadata.Extra
+	keyID, timestamp, nonce, signature := extra[AuthKeyIDKey], extra[AuthTimestampKey], extra[AuthNonceKey], extra[AuthSignatureKey]
+	if keyID == "" || timestamp == "" || nonce == "" || signature == "" {
+		return nil, NewRPCError(ErrCodeUnauthenticated, "missing hmac signature")
+	}
+
+	secret, ok := a.secrets[keyID]
+	if !ok {
+		return nil, NewRPCError(ErrCodeUnauthenticated, "unknown key id")
+	}
+	expected := signHMAC(secret, keyID, info.ServiceName, info.MethodName, info.Metadata.Version, timestamp, nonce, info.Payload)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, NewRPCError(ErrCodeUnauthenticated, "invalid hmac signature")
+	}
+
+	millis, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, NewRPCError(ErrCodeUnauthenticated, "invalid timestamp")
+	}
+	now := time.Now()
+	signedAt := time.UnixMilli(millis)
+	if signedAt.Before(now.Add(-a.maxSkew)) || signedAt.After(now.Add(a.maxSkew)) {
+		return nil, NewRPCError(ErrCodeUnauthenticated, "signature expired")
+	}
+
+	if !a.useNonce(keyID+":"+nonce, signedAt.Add(a.maxSkew), now) {
+		return nil, NewRPCError(ErrCodeUnauthenticated, "replayed request")
+	}
+	return WithAuthInfo(ctx, &AuthInfo{Scheme: "hmac", Subject: keyID}), nil
+}
+
+// useNonce 记录随机数，随机数在过期前已被使用时返回false
+// 过期时间之后的重放请求会因时间戳超出范围被拒绝，因此只需保留时间窗口内的随机数
+func (a *hmacAuthenticator) useNonce(nonce string, expiry, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if now.After(a.sweep) {
+		for n, exp := range a.nonces {
+			if now.After(exp) {
+				delete(a.nonces, n)
+			}
+		}
+		a.sweep = now.Add(a.maxSkew)
+	}
+
+	if exp, ok := a.nonces[nonce]; ok && !now.After(exp) {
+		return false
+	}
+	a.nonces[nonce] = expiry
+	return true
+}
@@ -0,0 +1,210 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/cluster/failover"
+	"github.com/fyerfyer/fyer-rpc/naming"
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AuthService 返回认证通过的调用方身份
+type AuthService struct{}
+
+func (s *AuthService) WhoAmI(ctx context.Context, req *BenchRequest) (*BenchResponse, error) {
+	info, ok := AuthInfoFromContext(ctx)
+	if !ok {
+		return nil, NewRPCError(ErrCodeInternal, "missing auth info")
+	}
+	return &BenchResponse{Value: info.Scheme + ":" + info.Subject}, nil
+}
+
+// tlsOnlyCredentials 要求TLS连接的认证信息
+type tlsOnlyCredentials struct{}
+
+func (tlsOnlyCredentials) GetRequestMetadata(ctx context.Context, serviceName, methodName string) (map[string]string, error) {
+	return nil, nil
+}
+
+func (tlsOnlyCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+// wrappedErrorAuthenticator 返回包装过的RPCError
+type wrappedErrorAuthenticator struct{}
+
+func (wrappedErrorAuthenticator) Authenticate(ctx context.Context, info *ServerInfo) (context.Context, error) {
+	return nil, fmt.Errorf("quota check: %w", NewRPCError(ErrCodeResourceExhausted, "quota exceeded"))
+}
+
+func TestTokenAuthentication(t *testing.T) {
+	server := NewServer(WithAuthenticator(NewTokenAuthenticator(map[string]string{"secret-token": "order"})))
+	require.NoError(t, server.RegisterService(&AuthService{}))
+	require.NoError(t, server.RegisterService(&LogService{}))
+	addr := startTestServer(t, server)
+
+	t.Run("valid token", func(t *testing.T) {
+		client, err := NewClient(addr, WithPerRPCCredentials(NewTokenCredentials("secret-token")))
+		require.NoError(t, err)
+		defer client.Close()
+
+		data, err := client.Call("AuthService", "WhoAmI", &BenchRequest{})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"bearer:order"}`, string(data))
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		client, err := NewClient(addr, WithPerRPCCredentials(NewTokenCredentials("wrong")))
+		require.NoError(t, err)
+		defer client.Close()
+
+		_, err = client.Call("AuthService", "WhoAmI", &BenchRequest{})
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+		assert.Contains(t, err.Error(), "invalid bearer token")
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		client, err := NewClient(addr)
+		require.NoError(t, err)
+		defer client.Close()
+
+		// 未认证时不暴露服务是否存在
+		_, err = client.Call("NoSuchService", "WhoAmI", &BenchRequest{})
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+
+		stream, err := client.NewStream(context.Background(), "LogService", "Tail")
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&TailRequest{Lines: 1}))
+		assert.ErrorIs(t, stream.Recv(&LogLine{}), ErrUnauthenticated)
+	})
+
+	t.Run("failover keeps credentials", func(t *testing.T) {
+		client, err := NewClient(addr, WithPerRPCCredentials(NewTokenCredentials("secret-token")), WithFailover(failover.DefaultConfig))
+		require.NoError(t, err)
+		defer client.Close()
+
+		instances := []*naming.Instance{{ID: "auth-1", Address: addr, Status: naming.StatusEnabled}}
+		data, err := client.CallWithFailover(context.Background(), "AuthService", "WhoAmI", &BenchRequest{}, instances)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"bearer:order"}`, string(data))
+	})
+
+	t.Run("transport security required", func(t *testing.T) {
+		_, err := NewClient(addr, WithPerRPCCredentials(tlsOnlyCredentials{}))
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+}
+
+func TestHMACAuthentication(t *testing.T) {
+	secrets := map[string][]byte{"order": []byte("shared-secret")}
+	server := NewServer(WithAuthenticator(NewHMACAuthenticator(secrets)))
+	require.NoError(t, server.RegisterService(&AuthService{}))
+	addr := startTestServer(t, server)
+
+	t.Run("valid signature", func(t *testing.T) {
+		client, err := NewClient(addr, WithPerRPCCredentials(NewHMACCredentials("order", []byte("shared-secret"))))
+		require.NoError(t, err)
+		defer client.Close()
+
+		for i := 0; i < 3; i++ {
+			data, err := client.Call("AuthService", "WhoAmI", &BenchRequest{})
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"Value":"hmac:order"}`, string(data))
+		}
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		client, err := NewClient(addr, WithPerRPCCredentials(NewHMACCredentials("order", []byte("guess"))))
+		require.NoError(t, err)
+		defer client.Close()
+
+		_, err = client.Call("AuthService", "WhoAmI", &BenchRequest{})
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+		assert.Contains(t, err.Error(), "invalid hmac signature")
+	})
+
+	authenticator := NewHMACAuthenticator(secrets, WithMaxClockSkew(time.Minute))
+	info := func(extra map[string]string) *ServerInfo {
+		return &ServerInfo{
+			ServiceName: "AuthService",
+			MethodName:  "WhoAmI",
+			Metadata:    &protocol.Metadata{ServiceName: "AuthService", MethodName: "WhoAmI", Extra: extra},
+		}
+	}
+
+	t.Run("replay rejected", func(t *testing.T) {
+		extra, err := NewHMACCredentials("order", []byte("shared-secret")).GetRequestMetadata(context.Background(), "AuthService", "WhoAmI")
+		require.NoError(t, err)
+
+		_, err = authenticator.Authenticate(context.Background(), info(extra))
+		require.NoError(t, err)
+		_, err = authenticator.Authenticate(context.Background(), info(extra))
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+		assert.Contains(t, err.Error(), "replayed request")
+	})
+
+	t.Run("expired timestamp", func(t *testing.T) {
+		timestamp := strconv.FormatInt(time.Now().Add(-2*time.Minute).UnixMilli(), 10)
+		extra := map[string]string{
+			AuthKeyIDKey:     "order",
+			AuthTimestampKey: timestamp,
+			AuthNonceKey:     "nonce",
+			AuthSignatureKey: signHMAC([]byte("shared-secret"), "order", "AuthService", "WhoAmI", "", timestamp, "nonce", nil),
+		}
+
+		_, err := authenticator.Authenticate(context.Background(), info(extra))
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+		assert.Contains(t, err.Error(), "signature expired")
+	})
+
+	t.Run("signature bound to method", func(t *testing.T) {
+		extra, err := NewHMACCredentials("order", []byte("shared-secret")).GetRequestMetadata(context.Background(), "AuthService", "Other")
+		require.NoError(t, err)
+
+		_, err = authenticator.Authenticate(context.Background(), info(extra))
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("signature bound to payload and version", func(t *testing.T) {
+		creds := NewHMACCredentials("order", []byte("shared-secret")).(requestCredentials)
+		metadata := &protocol.Metadata{ServiceName: "AuthService", MethodName: "WhoAmI", Version: "1.0.0"}
+		extra, err := creds.getRequestMetadata(context.Background(), metadata, []byte("payload"))
+		require.NoError(t, err)
+
+		signed := func(version string, payload []byte) *ServerInfo {
+			return &ServerInfo{
+				ServiceName: "AuthService",
+				MethodName:  "WhoAmI",
+				Metadata:    &protocol.Metadata{ServiceName: "AuthService", MethodName: "WhoAmI", Version: version, Extra: extra},
+				Payload:     payload,
+			}
+		}
+		_, err = authenticator.Authenticate(context.Background(), signed("1.0.0", []byte("tampered")))
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+		_, err = authenticator.Authenticate(context.Background(), signed("2.0.0", []byte("payload")))
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+		_, err = authenticator.Authenticate(context.Background(), signed("1.0.0", []byte("payload")))
+		assert.NoError(t, err)
+	})
+}
+
+func TestAuthenticatorWrappedError(t *testing.T) {
+	server := NewServer(WithAuthenticator(wrappedErrorAuthenticator{}))
+	require.NoError(t, server.RegisterService(&AuthService{}))
+	addr := startTestServer(t, server)
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// 包装后的RPCError保留原有错误码
+	_, err = client.Call("AuthService", "WhoAmI", &BenchRequest{})
+	assert.ErrorIs(t, err, ErrResourceExhausted)
+	assert.Contains(t, err.Error(), "quota exceeded")
+}
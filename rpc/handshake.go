@@ -0,0 +1,126 @@
+package rpc
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/fyerfyer/fyer-rpc/protocol/codec"
+	"github.com/fyerfyer/fyer-rpc/utils"
+)
+
+// defaultHandshakeTimeout 客户端等待服务端握手回复的默认时间
+const defaultHandshakeTimeout = 5 * time.Second
+
+// maxHandshakeSize 握手阶段接受的最小消息大小上限
+// 握手完成前按max(配置的限制, maxHandshakeSize)限制消息大小，避免过小的配置导致握手消息被拒绝
+const maxHandshakeSize = 64 << 10
+
+// handshakeLimit 返回握手阶段使用的消息大小限制，limit为0表示不限制
+func handshakeLimit(limit int) int {
+	if limit <= 0 || limit >= maxHandshakeSize {
+		return limit
+	}
+	return maxHandshakeSize
+}
+
+// defaultInstanceID 默认的实例ID：主机名-进程号
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// clientHandshake 发送本端握手信息并等待服务端回复，返回协商结果
+// 握手完成后连接使用协商出的协议版本；服务端不支持握手时返回nil，连接继续使用协议版本1
+func clientHandshake(conn *Connection, local *protocol.Handshake, timeout time.Duration) (*protocol.Handshake, error) {
+	jsonCodec := codec.GetCodec(codec.JSON)
+	data, err := jsonCodec.Encode(local)
+	if err != nil {
+		return nil, err
+	}
+	// 携带元数据，旧版本服务端会将其当作普通请求处理并回复错误
+	metadata := &protocol.Metadata{ServiceName: protocol.HandshakeServiceName}
+	if err := conn.Write("", "", protocol.TypeHandshake, protocol.SerializationTypeJSON, 0, metadata, data); err != nil {
+		return nil, err
+	}
+
+	if timeout > 0 {
+		conn.conn.SetReadDeadline(time.Now().Add(timeout))
+		defer conn.conn.SetReadDeadline(time.Time{})
+	}
+	reply, err := conn.Read()
+	if err != nil {
+		return nil, err
+	}
+	defer reply.Release()
+	if reply.Header.MessageType == protocol.TypeResponse && reply.Header.MessageID == 0 {
+		utils.Debug("Server does not support handshake, falling back to protocol version %d", protocol.MinProtocolVersion)
+		return nil, nil
+	}
+	if reply.Header.MessageType != protocol.TypeHandshake {
+		return nil, fmt.Errorf("%w: type %d", protocol.ErrUnexpectedMessage, reply.Header.MessageType)
+	}
+
+	remote := &protocol.Handshake{}
+	if err := jsonCodec.Decode(reply.Payload, remote); err != nil {
+		return nil, err
+	}
+	if remote.Error != "" {
+		return nil, fmt.Errorf("%w: %s", protocol.ErrHandshakeRejected, remote.Error)
+	}
+
+	negotiated, err := protocol.Negotiate(local, remote)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetVersion(negotiated.Version)
+	return negotiated, nil
+}
+
+// acceptHandshake 处理客户端的握手消息并回复本端握手信息，协商失败时回复拒绝原因并返回false
+func (s *Server) acceptHandshake(sc *serverConn, message *protocol.Message) bool {
+	defer message.Release()
+	jsonCodec := codec.GetCodec(codec.JSON)
+
+	local := protocol.NewHandshake()
+	local.MaxFrameSize = uint32(s.maxRequestSize)
+
+	remote := &protocol.Handshake{}
+	err := jsonCodec.Decode(message.Payload, remote)
+	var negotiated *protocol.Handshake
+	if err == nil {
+		negotiated, err = protocol.Negotiate(local, remote)
+	}
+
+	reply := local
+	if err != nil {
+		utils.Warn("Rejecting handshake from %s: %v", sc.conn.conn.RemoteAddr(), err)
+		reply = &protocol.Handshake{
+			Version:    local.Version,
+			MinVersion: local.MinVersion,
+			Error:      err.Error(),
+		}
+	}
+
+	data, encodeErr := jsonCodec.Encode(reply)
+	if encodeErr == nil {
+		encodeErr = sc.conn.Write("", "", protocol.TypeHandshake, protocol.SerializationTypeJSON, message.Header.MessageID, nil, data)
+	}
+	if encodeErr != nil {
+		utils.Error("Failed to send handshake: %v", encodeErr)
+		return false
+	}
+	if err != nil {
+		return false
+	}
+
+	utils.Debug("Handshake with %s (app=%s, instance=%s) negotiated version %d",
+		sc.conn.conn.RemoteAddr(), negotiated.AppName, negotiated.InstanceID, negotiated.Version)
+	sc.conn.SetVersion(negotiated.Version)
+	sc.setPeer(negotiated)
+	return true
+}
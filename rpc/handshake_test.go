@@ -0,0 +1,256 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/fyerfyer/fyer-rpc/protocol/codec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandshake(t *testing.T) {
+	server := NewServer(WithServerMessageSizeLimit(1024, 64))
+	require.NoError(t, server.RegisterService(&BenchService{}))
+	addr := startTestServer(t, server)
+
+	t.Run("negotiated", func(t *testing.T) {
+		client, err := NewClient(addr, WithIdentity("order", "order-1"))
+		require.NoError(t, err)
+		defer client.Close()
+
+		peer := client.Negotiated()
+		require.NotNil(t, peer)
+		assert.Equal(t, protocol.ProtocolVersion, peer.Version)
+		assert.True(t, peer.SupportsSerializer(protocol.SerializationTypeJSON))
+		assert.Equal(t, uint32(64), peer.MaxFrameSize)
+
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "hi"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"hi"}`, string(data))
+
+		// 超过服务端限制的请求在客户端直接被拒绝
+		_, err = client.Call("BenchService", "Echo", &BenchRequest{Value: strings.Repeat("x", 128)})
+		assert.ErrorIs(t, err, ErrResourceExhausted)
+		assert.Contains(t, err.Error(), "exceeds server limit 64")
+	})
+
+	t.Run("legacy client without handshake", func(t *testing.T) {
+		client, err := NewClient(addr, WithoutHandshake())
+		require.NoError(t, err)
+		defer client.Close()
+		assert.Nil(t, client.Negotiated())
+
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "legacy"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"legacy"}`, string(data))
+
+		// 服务端仍然会拒绝超限的请求
+		_, err = client.Call("BenchService", "Echo", &BenchRequest{Value: strings.Repeat("x", 128)})
+		assert.ErrorIs(t, err, ErrResourceExhausted)
+	})
+
+	t.Run("incompatible version rejected", func(t *testing.T) {
+		raw, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		conn := NewConnection(raw)
+		defer conn.Close()
+
+		jsonCodec := codec.GetCodec(codec.JSON)
+		data, err := jsonCodec.Encode(&protocol.Handshake{
+			Version:     protocol.ProtocolVersion + 2,
+			MinVersion:  protocol.ProtocolVersion + 1,
+			Serializers: []uint8{protocol.SerializationTypeJSON},
+		})
+		require.NoError(t, err)
+		require.NoError(t, conn.Write("", "", protocol.TypeHandshake, protocol.SerializationTypeJSON, 0, nil, data))
+
+		raw.SetReadDeadline(time.Now().Add(2 * time.Second))
+		reply, err := conn.Read()
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TypeHandshake, reply.Header.MessageType)
+
+		remote := &protocol.Handshake{}
+		require.NoError(t, jsonCodec.Decode(reply.Payload, remote))
+		assert.Contains(t, remote.Error, "incompatible protocol version")
+
+		// 拒绝握手后服务端关闭连接
+		_, err = conn.Read()
+		assert.Error(t, err)
+	})
+
+	t.Run("legacy server", func(t *testing.T) {
+		addr := startLegacyServer(t)
+
+		client, err := NewClient(addr)
+		require.NoError(t, err)
+		defer client.Close()
+		assert.Nil(t, client.Negotiated())
+
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "old"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"old"}`, string(data))
+	})
+}
+
+func TestMixedVersions(t *testing.T) {
+	t.Run("legacy client against new server", func(t *testing.T) {
+		service := newSlowService()
+		server := NewServer(WithServerKeepAlive(20*time.Millisecond, 2))
+		require.NoError(t, server.RegisterService(&BenchService{}))
+		require.NoError(t, server.RegisterService(service))
+		addr := startTestServer(t, server)
+
+		client := dialLegacyClient(t, addr)
+		reply := client.call(t, "BenchService", "Echo", &BenchRequest{Value: "old"})
+		assert.JSONEq(t, `{"Value":"old"}`, string(reply.Payload))
+
+		// 旧版本客户端超时后放弃等待，之后读到的仍是该请求的响应
+		id := client.send(t, "SlowService", "Wait", &SlowRequest{Delay: 100 * time.Millisecond})
+		time.Sleep(200 * time.Millisecond)
+		client.recv(t, id)
+		require.NoError(t, <-service.done)
+
+		reply = client.call(t, "BenchService", "Echo", &BenchRequest{Value: "still"})
+		assert.JSONEq(t, `{"Value":"still"}`, string(reply.Payload))
+
+		// 关闭服务器时不向旧版本客户端发送GoAway，连接直接关闭
+		require.NoError(t, server.Shutdown(context.Background()))
+		require.NoError(t, client.conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		_, err := client.proto.DecodeMessage(client.conn)
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("new client against legacy server", func(t *testing.T) {
+		addr := startLegacyServer(t)
+
+		client, err := NewClient(addr, WithKeepAlive(20*time.Millisecond, 2))
+		require.NoError(t, err)
+		defer client.Close()
+		require.Nil(t, client.Negotiated())
+
+		// 取消的调用不向旧版本服务端发送取消消息，迟到的响应被丢弃
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = client.CallWithTimeout(ctx, "SlowService", "Wait", &SlowRequest{Delay: 100 * time.Millisecond})
+		assert.ErrorIs(t, err, ErrTimeout)
+		time.Sleep(200 * time.Millisecond)
+
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "old"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"old"}`, string(data))
+
+		_, err = client.NewStream(context.Background(), "LogService", "Echo")
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+}
+
+// startLegacyServer 启动模拟不支持握手的旧版本服务端：
+// 与旧版本一样把每条消息都当作请求、按元数据中的服务名查找服务并依次同步处理，
+// 不检查元数据是否为空，收到没有元数据的控制消息时直接崩溃
+// 支持BenchService.Echo和按请求延迟响应的SlowService.Wait，其余服务回复服务不存在
+func startLegacyServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				proto := &protocol.DefaultProtocol{}
+				for {
+					msg, err := proto.DecodeMessage(conn)
+					if err != nil {
+						return
+					}
+					reply := &protocol.Message{
+						Header: protocol.Header{
+							MagicNumber:       protocol.MagicNumber,
+							Version:           protocol.MinProtocolVersion,
+							MessageType:       protocol.TypeResponse,
+							SerializationType: msg.Header.SerializationType,
+							MessageID:         msg.Header.MessageID,
+						},
+					}
+					switch msg.Metadata.ServiceName {
+					case "BenchService":
+						reply.Payload = msg.Payload
+					case "SlowService":
+						req := &SlowRequest{}
+						if err := codec.GetCodec(codec.JSON).Decode(msg.Payload, req); err != nil {
+							return
+						}
+						time.Sleep(req.Delay)
+						reply.Payload = []byte("{}")
+					default:
+						reply.Metadata = &protocol.Metadata{Error: "service not found: " + msg.Metadata.ServiceName}
+					}
+					if err := proto.EncodeMessage(reply, conn); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// legacyClient 模拟不支持握手的旧版本客户端：
+// 发送请求后把读到的下一条消息当作该请求的响应，不认识心跳等控制消息
+type legacyClient struct {
+	conn      net.Conn
+	proto     *protocol.DefaultProtocol
+	messageID uint64
+}
+
+func dialLegacyClient(t *testing.T, addr string) *legacyClient {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return &legacyClient{conn: conn, proto: &protocol.DefaultProtocol{}}
+}
+
+// send 发送请求并返回消息ID
+func (c *legacyClient) send(t *testing.T, serviceName, methodName string, args interface{}) uint64 {
+	data, err := codec.GetCodec(codec.JSON).Encode(args)
+	require.NoError(t, err)
+	c.messageID++
+	msg := &protocol.Message{
+		Header: protocol.Header{
+			MagicNumber:       protocol.MagicNumber,
+			Version:           protocol.MinProtocolVersion,
+			MessageType:       protocol.TypeRequest,
+			SerializationType: protocol.SerializationTypeJSON,
+			MessageID:         c.messageID,
+		},
+		Metadata: &protocol.Metadata{ServiceName: serviceName, MethodName: methodName},
+		Payload:  data,
+	}
+	require.NoError(t, c.proto.EncodeMessage(msg, c.conn))
+	return c.messageID
+}
+
+// recv 读取下一条消息，要求它是messageID对应的响应
+func (c *legacyClient) recv(t *testing.T, messageID uint64) *protocol.Message {
+	require.NoError(t, c.conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	msg, err := c.proto.DecodeMessage(c.conn)
+	require.NoError(t, err)
+	require.Equal(t, protocol.TypeResponse, msg.Header.MessageType, "legacy client got a non-response frame")
+	require.Equal(t, messageID, msg.Header.MessageID)
+	return msg
+}
+
+// call 发送请求并等待响应
+func (c *legacyClient) call(t *testing.T, serviceName, methodName string, args interface{}) *protocol.Message {
+	return c.recv(t, c.send(t, serviceName, methodName, args))
+}
@@ -0,0 +1,108 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/fyerfyer/fyer-rpc/utils"
+)
+
+// ServerInfo 拦截器可见的服务端调用信息
+type ServerInfo struct {
+	ServiceName string             // 服务名称
+	MethodName  string             // 方法名称
+	Metadata    *protocol.Metadata // 请求元数据
+	Payload     []byte             // 序列化后的请求体，仅在认证时提供，流式调用为空
+}
+
+// Handler 服务端请求处理函数，最终调用服务方法
+type Handler func(ctx context.Context, req interface{}) (interface{}, error)
+
+// ServerInterceptor 服务端一元拦截器
+// 拦截器可以在调用handler前后执行额外逻辑，也可以不调用handler直接返回
+type ServerInterceptor func(ctx context.Context, info *ServerInfo, req interface{}, handler Handler) (interface{}, error)
+
+// WithServerInterceptors 添加服务端拦截器，按添加顺序由外向内执行
+func WithServerInterceptors(interceptors ...ServerInterceptor) ServerOption {
+	return func(s *Server) {
+		s.interceptors = append(s.interceptors, interceptors...)
+	}
+}
+
+// chainServerInterceptors 将多个拦截器组合为一个，第一个拦截器位于最外层
+func chainServerInterceptors(interceptors []ServerInterceptor) ServerInterceptor {
+	switch len(interceptors) {
+	case 0:
+		return nil
+	case 1:
+		return interceptors[0]
+	}
+
+	return func(ctx context.Context, info *ServerInfo, req interface{}, handler Handler) (interface{}, error) {
+		return interceptors[0](ctx, info, req, chainServerHandler(interceptors, 0, info, handler))
+	}
+}
+
+// chainServerHandler 构造第curr个拦截器之后的处理链
+func chainServerHandler(interceptors []ServerInterceptor, curr int, info *ServerInfo, finalHandler Handler) Handler {
+	if curr == len(interceptors)-1 {
+		return finalHandler
+	}
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return interceptors[curr+1](ctx, info, req, chainServerHandler(interceptors, curr+1, info, finalHandler))
+	}
+}
+
+// RecoveryInterceptor 捕获处理器中的panic并转换为内部错误，避免工作协程崩溃
+func RecoveryInterceptor() ServerInterceptor {
+	return func(ctx context.Context, info *ServerInfo, req interface{}, handler Handler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				utils.Error("Panic in %s.%s: %v\n%s", info.ServiceName, info.MethodName, r, debug.Stack())
+				resp = nil
+				err = NewRPCError(ErrCodeInternal, fmt.Sprintf("panic: %v", r))
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// Invoker 发起一次远程调用，并将响应解码到reply
+type Invoker func(ctx context.Context, serviceName, methodName string, req, reply interface{}, opts ...CallOption) error
+
+// ClientInterceptor 客户端一元拦截器
+// 拦截器可以修改上下文中的元数据、统计耗时，或不调用invoker直接填充reply返回；单向调用的reply为nil
+type ClientInterceptor func(ctx context.Context, serviceName, methodName string, req, reply interface{}, invoker Invoker, opts ...CallOption) error
+
+// WithInterceptors 添加客户端拦截器，按添加顺序由外向内执行
+func WithInterceptors(interceptors ...ClientInterceptor) ClientOption {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// chainClientInterceptors 将多个拦截器组合为一个，第一个拦截器位于最外层
+func chainClientInterceptors(interceptors []ClientInterceptor) ClientInterceptor {
+	switch len(interceptors) {
+	case 0:
+		return nil
+	case 1:
+		return interceptors[0]
+	}
+
+	return func(ctx context.Context, serviceName, methodName string, req, reply interface{}, invoker Invoker, opts ...CallOption) error {
+		return interceptors[0](ctx, serviceName, methodName, req, reply, chainClientInvoker(interceptors, 0, invoker), opts...)
+	}
+}
+
+// chainClientInvoker 构造第curr个拦截器之后的调用链
+func chainClientInvoker(interceptors []ClientInterceptor, curr int, finalInvoker Invoker) Invoker {
+	if curr == len(interceptors)-1 {
+		return finalInvoker
+	}
+	return func(ctx context.Context, serviceName, methodName string, req, reply interface{}, opts ...CallOption) error {
+		return interceptors[curr+1](ctx, serviceName, methodName, req, reply, chainClientInvoker(interceptors, curr+1, finalInvoker), opts...)
+	}
+}
@@ -0,0 +1,207 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PanicService 处理器会panic的测试服务
+type PanicService struct{}
+
+func (s *PanicService) Boom(ctx context.Context, req *BenchRequest) (*BenchResponse, error) {
+	panic("boom")
+}
+
+func TestServerInterceptors(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	var seen *ServerInfo
+
+	record := func(name string) ServerInterceptor {
+		return func(ctx context.Context, info *ServerInfo, req interface{}, handler Handler) (interface{}, error) {
+			mu.Lock()
+			order = append(order, name+":before")
+			seen = info
+			mu.Unlock()
+
+			resp, err := handler(ctx, req)
+
+			mu.Lock()
+			order = append(order, name+":after")
+			mu.Unlock()
+			return resp, err
+		}
+	}
+
+	// 对特定请求直接返回，不调用处理器
+	shortCircuit := func(ctx context.Context, info *ServerInfo, req interface{}, handler Handler) (interface{}, error) {
+		if r, ok := req.(*BenchRequest); ok && r.Value == "denied" {
+			return nil, NewRPCError(ErrCodeInvalidParam, "request denied")
+		}
+		return handler(ctx, req)
+	}
+
+	server := NewServer(WithServerInterceptors(RecoveryInterceptor(), record("first"), record("second"), shortCircuit))
+	require.NoError(t, server.RegisterService(&BenchService{}))
+	require.NoError(t, server.RegisterService(&PanicService{}))
+	addr := startTestServer(t, server)
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	t.Run("chain order and info", func(t *testing.T) {
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "hello"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"hello"}`, string(data))
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, []string{"first:before", "second:before", "second:after", "first:after"}, order)
+		assert.Equal(t, "BenchService", seen.ServiceName)
+		assert.Equal(t, "Echo", seen.MethodName)
+		assert.Equal(t, "Echo", seen.Metadata.MethodName)
+	})
+
+	t.Run("short circuit", func(t *testing.T) {
+		_, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "denied"})
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+
+	t.Run("recover from panic", func(t *testing.T) {
+		_, err := client.Call("PanicService", "Boom", &BenchRequest{})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInternal)
+		assert.Contains(t, err.Error(), "boom")
+
+		// 服务器在panic后仍可正常处理请求
+		_, err = client.Call("BenchService", "Echo", &BenchRequest{Value: "still alive"})
+		assert.NoError(t, err)
+	})
+}
+
+// MetadataService 返回请求元数据的测试服务
+type MetadataService struct{}
+
+func (s *MetadataService) Get(ctx context.Context, req *BenchRequest) (*BenchResponse, error) {
+	md, ok := IncomingMetadata(ctx)
+	if !ok {
+		return nil, NewRPCError(ErrCodeInternal, "metadata missing")
+	}
+	return &BenchResponse{Value: md.Extra[req.Value]}, nil
+}
+
+// MetadataProxy 访问MetadataService的代理
+type MetadataProxy struct {
+	Get func(ctx context.Context, req *BenchRequest) (*BenchResponse, error)
+}
+
+func TestClientInterceptors(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.RegisterService(&MetadataService{}))
+	addr := startTestServer(t, server)
+
+	var mu sync.Mutex
+	var order []string
+	var calls int
+
+	record := func(name string) ClientInterceptor {
+		return func(ctx context.Context, serviceName, methodName string, req, reply interface{}, invoker Invoker, opts ...CallOption) error {
+			mu.Lock()
+			order = append(order, name+":before")
+			mu.Unlock()
+
+			err := invoker(ctx, serviceName, methodName, req, reply, opts...)
+
+			mu.Lock()
+			order = append(order, name+":after")
+			mu.Unlock()
+			return err
+		}
+	}
+
+	// 注入请求元数据
+	injectMetadata := func(ctx context.Context, serviceName, methodName string, req, reply interface{}, invoker Invoker, opts ...CallOption) error {
+		ctx = AppendToOutgoingContext(ctx, "x-trace-id", "trace-1")
+		return invoker(ctx, serviceName, methodName, req, reply, opts...)
+	}
+
+	// 统计实际发出的调用次数
+	counter := func(ctx context.Context, serviceName, methodName string, req, reply interface{}, invoker Invoker, opts ...CallOption) error {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		return invoker(ctx, serviceName, methodName, req, reply, opts...)
+	}
+
+	// 命中缓存时直接填充reply返回
+	cache := func(ctx context.Context, serviceName, methodName string, req, reply interface{}, invoker Invoker, opts ...CallOption) error {
+		if r, ok := req.(*BenchRequest); ok && r.Value == "cached" {
+			switch v := reply.(type) {
+			case *BenchResponse:
+				v.Value = "from-cache"
+			case *[]byte:
+				*v = []byte(`{"Value":"from-cache"}`)
+			}
+			return nil
+		}
+		return invoker(ctx, serviceName, methodName, req, reply, opts...)
+	}
+
+	opts := []ClientOption{WithInterceptors(record("first"), record("second"), cache, injectMetadata, counter)}
+
+	client, err := NewClient(addr, opts...)
+	require.NoError(t, err)
+	defer client.Close()
+
+	reset := func() {
+		mu.Lock()
+		order = nil
+		calls = 0
+		mu.Unlock()
+	}
+
+	t.Run("chain order and metadata", func(t *testing.T) {
+		reset()
+		reply := &BenchResponse{}
+		err := client.Invoke(context.Background(), "MetadataService", "Get", &BenchRequest{Value: "x-trace-id"}, reply)
+		require.NoError(t, err)
+		assert.Equal(t, "trace-1", reply.Value)
+		assert.Equal(t, []string{"first:before", "second:before", "second:after", "first:after"}, order)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("caller metadata", func(t *testing.T) {
+		ctx := AppendToOutgoingContext(context.Background(), "x-user", "alice")
+		reply := &BenchResponse{}
+		require.NoError(t, client.Invoke(ctx, "MetadataService", "Get", &BenchRequest{Value: "x-user"}, reply))
+		assert.Equal(t, "alice", reply.Value)
+	})
+
+	t.Run("short circuit through Call", func(t *testing.T) {
+		reset()
+		data, err := client.Call("MetadataService", "Get", &BenchRequest{Value: "cached"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"from-cache"}`, string(data))
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		reset()
+		proxy := &MetadataProxy{}
+		require.NoError(t, InitProxy(addr, proxy, WithServiceName("MetadataService"), WithProxyClientOptions(opts...)))
+
+		resp, err := proxy.Get(context.Background(), &BenchRequest{Value: "x-trace-id"})
+		require.NoError(t, err)
+		assert.Equal(t, "trace-1", resp.Value)
+
+		resp, err = proxy.Get(context.Background(), &BenchRequest{Value: "cached"})
+		require.NoError(t, err)
+		assert.Equal(t, "from-cache", resp.Value)
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/fyerfyer/fyer-rpc/utils"
+)
+
+// keepAlive 连接心跳检测，客户端和服务端共用
+// 连接空闲interval后发送心跳，连续maxMissed个周期没有收到对端的任何消息时认为对端已失效并关闭连接
+// 收到的任何消息（包括心跳响应）都视为对端存活，因此有流量的连接不会发送心跳
+func (c *Connection) keepAlive(interval time.Duration, maxMissed int) {
+	if interval <= 0 {
+		return
+	}
+	if maxMissed <= 0 {
+		maxMissed = 1
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	missed := 0
+	var pingID uint64
+	var lastPing int64 // 最近一次发送心跳的时间(UnixNano)
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+		}
+
+		// 上次心跳之后收到过消息，说明对端存活
+		lastRead := atomic.LoadInt64(&c.lastRead)
+		if lastRead > lastPing {
+			missed = 0
+			if time.Since(time.Unix(0, lastRead)) < interval {
+				continue
+			}
+		}
+
+		if missed >= maxMissed {
+			utils.Warn("Closing connection to %s: no response after %d keepalive probes", c.conn.RemoteAddr(), missed)
+			c.Close()
+			return
+		}
+
+		pingID++
+		lastPing = time.Now().UnixNano()
+		if err := c.Write("", "", protocol.TypePing, protocol.SerializationTypeJSON, pingID, nil, nil); err != nil {
+			utils.Debug("Failed to send keepalive ping: %v", err)
+		}
+		missed++
+	}
+}
+
+// pong 回复对端的心跳请求
+func (c *Connection) pong(messageID uint64) {
+	if err := c.Write("", "", protocol.TypePong, protocol.SerializationTypeJSON, messageID, nil, nil); err != nil {
+		utils.Debug("Failed to send keepalive pong: %v", err)
+	}
+}
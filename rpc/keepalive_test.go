@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/fyerfyer/fyer-rpc/protocol/codec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepAlive(t *testing.T) {
+	t.Run("idle connection stays alive", func(t *testing.T) {
+		server := NewServer(WithServerKeepAlive(20*time.Millisecond, 2))
+		require.NoError(t, server.RegisterService(&BenchService{}))
+		addr := startTestServer(t, server)
+
+		client, err := NewClient(addr, WithKeepAlive(20*time.Millisecond, 2))
+		require.NoError(t, err)
+		defer client.Close()
+
+		time.Sleep(200 * time.Millisecond)
+
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "alive"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"alive"}`, string(data))
+	})
+
+	t.Run("client detects unresponsive server", func(t *testing.T) {
+		// 只接受连接而不读写的服务端，模拟半开连接
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		// 完成握手后不再读写
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				acceptTestHandshake(t, NewConnection(conn))
+			}
+		}()
+
+		pool := NewConnPool(listener.Addr().String(), 1, time.Minute, WithClientOptions(WithKeepAlive(20*time.Millisecond, 2)))
+		defer pool.Close()
+
+		client, err := pool.Get()
+		require.NoError(t, err)
+		pool.Put(client)
+
+		assert.Eventually(t, func() bool {
+			return !client.available()
+		}, 2*time.Second, 10*time.Millisecond)
+
+		_, err = client.Call("BenchService", "Echo", &BenchRequest{})
+		assert.ErrorIs(t, err, ErrShutdown)
+
+		// 连接池不再返回失效的连接
+		fresh, err := pool.Get()
+		require.NoError(t, err)
+		defer fresh.Close()
+		assert.NotSame(t, client, fresh)
+	})
+
+	t.Run("server reaps unresponsive client", func(t *testing.T) {
+		server := NewServer(WithServerKeepAlive(20*time.Millisecond, 2))
+		require.NoError(t, server.RegisterService(&BenchService{}))
+		addr := startTestServer(t, server)
+
+		// 完成握手后不回复心跳的客户端
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+
+		connection := NewConnection(conn)
+		data, err := codec.GetCodec(codec.JSON).Encode(protocol.NewHandshake())
+		require.NoError(t, err)
+		require.NoError(t, connection.Write("", "", protocol.TypeHandshake, protocol.SerializationTypeJSON, 0, nil, data))
+		reply, err := connection.Read()
+		require.NoError(t, err)
+		require.Equal(t, protocol.TypeHandshake, reply.Header.MessageType)
+
+		pings := 0
+		for {
+			msg, err := connection.Read()
+			if err != nil {
+				assert.ErrorIs(t, err, io.EOF)
+				break
+			}
+			assert.Equal(t, protocol.TypePing, msg.Header.MessageType)
+			pings++
+		}
+		assert.Equal(t, 2, pings)
+	})
+
+	t.Run("legacy client is not pinged", func(t *testing.T) {
+		server := NewServer(WithServerKeepAlive(20*time.Millisecond, 2))
+		require.NoError(t, server.RegisterService(&BenchService{}))
+		addr := startTestServer(t, server)
+
+		// 旧版本客户端会把心跳当作响应，服务端也不应因其不回复心跳而断开连接
+		client := dialLegacyClient(t, addr)
+		client.call(t, "BenchService", "Echo", &BenchRequest{Value: "before"})
+		time.Sleep(200 * time.Millisecond)
+		reply := client.call(t, "BenchService", "Echo", &BenchRequest{Value: "after"})
+		assert.JSONEq(t, `{"Value":"after"}`, string(reply.Payload))
+	})
+
+	t.Run("legacy server is not pinged", func(t *testing.T) {
+		addr := startLegacyServer(t)
+
+		client, err := NewClient(addr, WithKeepAlive(20*time.Millisecond, 2))
+		require.NoError(t, err)
+		defer client.Close()
+		require.Nil(t, client.Negotiated())
+
+		time.Sleep(200 * time.Millisecond)
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "alive"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"alive"}`, string(data))
+	})
+}
+
+// acceptTestHandshake 读取客户端的握手消息并回复本端的握手信息
+func acceptTestHandshake(t *testing.T, conn *Connection) {
+	if _, err := conn.Read(); err != nil {
+		return
+	}
+	data, err := codec.GetCodec(codec.JSON).Encode(protocol.NewHandshake())
+	require.NoError(t, err)
+	_ = conn.Write("", "", protocol.TypeHandshake, protocol.SerializationTypeJSON, 0, nil, data)
+}
@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+)
+
+type outgoingMetadataKey struct{}
+
+type incomingMetadataKey struct{}
+
+// AppendToOutgoingContext 向上下文追加请求元数据，kv为成对的键和值
+// 元数据随调用写入 Metadata.Extra 传递给服务端
+func AppendToOutgoingContext(ctx context.Context, kv ...string) context.Context {
+	if len(kv)%2 == 1 {
+		panic("rpc: AppendToOutgoingContext got an odd number of input pairs")
+	}
+
+	md := make(map[string]string)
+	for k, v := range OutgoingMetadata(ctx) {
+		md[k] = v
+	}
+	for i := 0; i < len(kv); i += 2 {
+		md[kv[i]] = kv[i+1]
+	}
+	return context.WithValue(ctx, outgoingMetadataKey{}, md)
+}
+
+// OutgoingMetadata 获取上下文中待发送的请求元数据，返回值不应被修改
+func OutgoingMetadata(ctx context.Context) map[string]string {
+	md, _ := ctx.Value(outgoingMetadataKey{}).(map[string]string)
+	return md
+}
+
+// IncomingMetadata 在服务端获取客户端发送的请求元数据
+func IncomingMetadata(ctx context.Context) (*protocol.Metadata, bool) {
+	md, ok := ctx.Value(incomingMetadataKey{}).(*protocol.Metadata)
+	return md, ok
+}
+
+// withIncomingMetadata 将请求元数据附加到服务端处理上下文
+func withIncomingMetadata(ctx context.Context, md *protocol.Metadata) context.Context {
+	return context.WithValue(ctx, incomingMetadataKey{}, md)
+}
@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Point 值类型传递的领域类型
+type Point struct {
+	X, Y int
+}
+
+// GeometryService 使用多种方法签名的服务
+type GeometryService struct {
+	deleted chan string
+}
+
+// Move 值类型的请求和响应
+func (s *GeometryService) Move(ctx context.Context, p Point) (Point, error) {
+	return Point{X: p.X + 1, Y: p.Y + 1}, nil
+}
+
+// Sum 不带上下文，请求为切片
+func (s *GeometryService) Sum(values []int) (int, error) {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total, nil
+}
+
+// Index 响应为映射
+func (s *GeometryService) Index(ctx context.Context, names []string) (map[string]int, error) {
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	return index, nil
+}
+
+// Delete 只返回error
+func (s *GeometryService) Delete(ctx context.Context, name string) error {
+	if name == "" {
+		return NewRPCError(ErrCodeInvalidParam, "empty name")
+	}
+	s.deleted <- name
+	return nil
+}
+
+// Close 不是RPC方法，注册时被拒绝
+func (s *GeometryService) Close() {}
+
+// Watch 响应为通道，注册时被拒绝
+func (s *GeometryService) Watch(ctx context.Context, p *Point) (chan Point, error) {
+	return nil, nil
+}
+
+// GeometryProxy 几何服务代理
+type GeometryProxy struct {
+	Move   func(ctx context.Context, p Point) (Point, error)
+	Index  func(ctx context.Context, names []string) (map[string]int, error)
+	Delete func(ctx context.Context, name string) error
+}
+
+func TestMethodSignatures(t *testing.T) {
+	service := &GeometryService{deleted: make(chan string, 10)}
+	server := NewServer()
+	require.NoError(t, server.RegisterService(service))
+	addr := startTestServer(t, server)
+
+	t.Run("rejected methods", func(t *testing.T) {
+		desc := server.Services()[0]
+		var valid []string
+		for name := range desc.Methods {
+			valid = append(valid, name)
+		}
+		sort.Strings(valid)
+		assert.Equal(t, []string{"Delete", "Index", "Move", "Sum"}, valid)
+
+		require.Len(t, desc.Rejected, 2)
+		assert.Contains(t, desc.Rejected["Close"].Error(), "expected 2 or 3 arguments")
+		assert.Contains(t, desc.Rejected["Watch"].Error(), "cannot be serialized")
+	})
+
+	t.Run("no valid methods", func(t *testing.T) {
+		err := NewServer().RegisterService(&struct{ GeometryProxy }{})
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	t.Run("client", func(t *testing.T) {
+		var p Point
+		require.NoError(t, client.Invoke(ctx, "GeometryService", "Move", Point{X: 1, Y: 2}, &p))
+		assert.Equal(t, Point{X: 2, Y: 3}, p)
+
+		var sum int
+		require.NoError(t, client.Invoke(ctx, "GeometryService", "Sum", []int{1, 2, 3}, &sum))
+		assert.Equal(t, 6, sum)
+
+		var index map[string]int
+		require.NoError(t, client.Invoke(ctx, "GeometryService", "Index", []string{"a", "b"}, &index))
+		assert.Equal(t, map[string]int{"a": 0, "b": 1}, index)
+
+		require.NoError(t, client.Invoke(ctx, "GeometryService", "Delete", "c", nil))
+		assert.Equal(t, "c", <-service.deleted)
+
+		err := client.Invoke(ctx, "GeometryService", "Delete", "", nil)
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		var proxy GeometryProxy
+		require.NoError(t, InitProxy(addr, &proxy, WithServiceName("GeometryService")))
+
+		p, err := proxy.Move(ctx, Point{X: 5})
+		require.NoError(t, err)
+		assert.Equal(t, Point{X: 6, Y: 1}, p)
+
+		index, err := proxy.Index(ctx, []string{"x"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"x": 0}, index)
+
+		require.NoError(t, proxy.Delete(ctx, "d"))
+		assert.Equal(t, "d", <-service.deleted)
+	})
+}
@@ -0,0 +1,125 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AuditEvent 单向调用的请求
+type AuditEvent struct {
+	Action string
+}
+
+// AuditAck 审计服务的响应，单向调用时被丢弃
+type AuditAck struct{}
+
+// AuditService 记录收到的审计事件
+type AuditService struct {
+	events chan string
+}
+
+func (s *AuditService) Record(ctx context.Context, event *AuditEvent) (*AuditAck, error) {
+	s.events <- event.Action
+	return &AuditAck{}, nil
+}
+
+func (s *AuditService) Reject(ctx context.Context, event *AuditEvent) (*AuditAck, error) {
+	return nil, NewRPCError(ErrCodeInvalidParam, "rejected: "+event.Action)
+}
+
+// AuditProxy 审计服务代理
+type AuditProxy struct {
+	Record func(ctx context.Context, event *AuditEvent) error
+	Reject func(ctx context.Context, event *AuditEvent) error
+}
+
+func TestOneway(t *testing.T) {
+	service := &AuditService{events: make(chan string, 10)}
+	server := NewServer()
+	require.NoError(t, server.RegisterService(service))
+	addr := startTestServer(t, server)
+
+	receive := func(t *testing.T) string {
+		select {
+		case action := <-service.events:
+			return action
+		case <-time.After(2 * time.Second):
+			t.Fatal("one-way call was not delivered")
+			return ""
+		}
+	}
+
+	t.Run("notify", func(t *testing.T) {
+		client, err := NewClient(addr)
+		require.NoError(t, err)
+		defer client.Close()
+
+		require.NoError(t, client.Notify(context.Background(), "AuditService", "Record", &AuditEvent{Action: "login"}))
+		assert.Equal(t, "login", receive(t))
+
+		// 处理失败的单向调用不影响连接上的后续调用
+		require.NoError(t, client.Notify(context.Background(), "AuditService", "Reject", &AuditEvent{Action: "x"}))
+		require.NoError(t, client.Notify(context.Background(), "NoSuchService", "Record", &AuditEvent{}))
+		var ack AuditAck
+		require.NoError(t, client.Invoke(context.Background(), "AuditService", "Record", &AuditEvent{Action: "sync"}, &ack))
+		assert.Equal(t, "sync", receive(t))
+	})
+
+	t.Run("closed client", func(t *testing.T) {
+		client, err := NewClient(addr)
+		require.NoError(t, err)
+		client.Close()
+
+		err = client.Notify(context.Background(), "AuditService", "Record", &AuditEvent{})
+		assert.ErrorIs(t, err, ErrShutdown)
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		var proxy AuditProxy
+		require.NoError(t, InitProxy(addr, &proxy, WithServiceName("AuditService"), WithOneway("Record")))
+
+		require.NoError(t, proxy.Record(context.Background(), &AuditEvent{Action: "logout"}))
+		assert.Equal(t, "logout", receive(t))
+
+		// 未设置为单向调用的方法等待服务端返回错误
+		err := proxy.Reject(context.Background(), &AuditEvent{Action: "delete"})
+		assert.ErrorIs(t, err, ErrInvalidParam)
+		assert.Contains(t, err.Error(), "rejected: delete")
+	})
+
+	t.Run("interceptors", func(t *testing.T) {
+		var seen []string
+		record := func(ctx context.Context, serviceName, methodName string, req, reply interface{}, invoker Invoker, opts ...CallOption) error {
+			if reply == nil {
+				seen = append(seen, serviceName+"."+methodName)
+			}
+			return invoker(ctx, serviceName, methodName, req, reply, opts...)
+		}
+
+		client, err := NewClient(addr, WithInterceptors(record))
+		require.NoError(t, err)
+		defer client.Close()
+		require.NoError(t, client.Notify(context.Background(), "AuditService", "Record", &AuditEvent{Action: "audit"}))
+		assert.Equal(t, "audit", receive(t))
+
+		// 代理的单向方法同样经过客户端拦截器
+		var proxy AuditProxy
+		require.NoError(t, InitProxy(addr, &proxy, WithServiceName("AuditService"), WithOneway("Record"), WithProxyClientOptions(WithInterceptors(record))))
+		require.NoError(t, proxy.Record(context.Background(), &AuditEvent{Action: "proxied"}))
+		assert.Equal(t, "proxied", receive(t))
+
+		assert.Equal(t, []string{"AuditService.Record", "AuditService.Record"}, seen)
+	})
+
+	t.Run("invalid one-way signature", func(t *testing.T) {
+		var proxy struct {
+			Record func(ctx context.Context, event *AuditEvent) (*AuditAck, error)
+		}
+		err := InitProxy(addr, &proxy, WithServiceName("AuditService"), WithOneway("Record"))
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+}
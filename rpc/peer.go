@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+)
+
+// Peer 服务端处理请求时对端客户端的信息
+type Peer struct {
+	Addr       net.Addr             // 客户端地址
+	AppName    string               // 握手时客户端声明的应用名称
+	InstanceID string               // 握手时客户端声明的实例ID
+	TLS        *tls.ConnectionState // TLS连接状态，未使用TLS时为nil
+}
+
+// Certificate 返回经过验证的客户端证书，未使用TLS或客户端未提供证书时返回nil
+func (p *Peer) Certificate() *x509.Certificate {
+	if p.TLS == nil || len(p.TLS.VerifiedChains) == 0 || len(p.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return p.TLS.VerifiedChains[0][0]
+}
+
+type peerKey struct{}
+
+// PeerFromContext 在服务端获取发起请求的客户端信息
+func PeerFromContext(ctx context.Context) (*Peer, bool) {
+	p, ok := ctx.Value(peerKey{}).(*Peer)
+	return p, ok
+}
+
+// withPeer 将客户端信息附加到服务端处理上下文
+func withPeer(ctx context.Context, p *Peer) context.Context {
+	return context.WithValue(ctx, peerKey{}, p)
+}
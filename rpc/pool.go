@@ -1,64 +1,331 @@
 package rpc
 
 import (
+	"context"
 	"sync"
 	"time"
+
+	"github.com/fyerfyer/fyer-rpc/config"
 )
 
+// ErrPoolClosed 连接池关闭后获取连接返回的错误
+var ErrPoolClosed = NewRPCError(ErrCodeUnavailable, "connection pool is closed")
+
+// ConnPool 连接池
+// 打开的连接数不超过maxActive，达到上限后Get排队等待归还的连接；
+// 空闲连接最多保留maxIdle个，空闲超过idleTimeout或已失效的连接会被关闭
 type ConnPool struct {
 	mu          sync.Mutex
 	address     string
 	maxIdle     int
+	maxActive   int // 最大打开连接数，为0时不限制
 	idleTimeout time.Duration
-	conns       chan *Client
+	idle        []*idleConn    // 空闲连接，最近归还的在末尾
+	open        int            // 已打开的连接数，包括空闲和使用中的连接
+	waiters     []chan *Client // 等待连接的调用方，收到nil表示可以新建一个连接
+	closed      bool
+	clientOpts  []ClientOption // 创建连接时使用的客户端选项
+	stop        chan struct{}  // 关闭空闲连接清理协程
+
+	waitCount    int64         // 累计等待次数
+	waitDuration time.Duration // 累计等待时间
+}
+
+// idleConn 空闲连接
+type idleConn struct {
+	client *Client
+	since  time.Time // 放回连接池的时间
+}
+
+// PoolStats 连接池统计信息
+type PoolStats struct {
+	Open         int           // 已打开的连接数
+	Idle         int           // 空闲连接数
+	InUse        int           // 使用中的连接数
+	WaitCount    int64         // 累计等待连接的次数
+	WaitDuration time.Duration // 累计等待连接的时间
+}
+
+// PoolOption 连接池配置选项
+type PoolOption func(*ConnPool)
+
+// WithClientOptions 设置连接池创建客户端时使用的选项
+func WithClientOptions(opts ...ClientOption) PoolOption {
+	return func(p *ConnPool) {
+		p.clientOpts = append(p.clientOpts, opts...)
+	}
 }
 
-func NewConnPool(address string, maxIdle int, idleTimeout time.Duration) *ConnPool {
-	return &ConnPool{
+// WithMaxActive 设置最大打开连接数，为0时不限制
+func WithMaxActive(maxActive int) PoolOption {
+	return func(p *ConnPool) {
+		if maxActive >= 0 {
+			p.maxActive = maxActive
+		}
+	}
+}
+
+func NewConnPool(address string, maxIdle int, idleTimeout time.Duration, opts ...PoolOption) *ConnPool {
+	pool := &ConnPool{
 		address:     address,
 		maxIdle:     maxIdle,
 		idleTimeout: idleTimeout,
-		conns:       make(chan *Client, maxIdle),
+		stop:        make(chan struct{}),
+	}
+
+	// 应用配置选项
+	for _, opt := range opts {
+		opt(pool)
+	}
+
+	if pool.idleTimeout > 0 {
+		go pool.cleaner()
+	}
+
+	return pool
+}
+
+// NewConnPoolFromConfig 根据客户端配置创建连接池
+// 最大打开连接数为PoolSize，且不超过ConnectionLimit
+func NewConnPoolFromConfig(address string, cfg *config.ClientConfig, opts ...PoolOption) *ConnPool {
+	maxActive := cfg.PoolSize
+	if cfg.ConnectionLimit > 0 && (maxActive <= 0 || cfg.ConnectionLimit < maxActive) {
+		maxActive = cfg.ConnectionLimit
 	}
+
+	poolOpts := []PoolOption{
+		WithMaxActive(maxActive),
+		WithClientOptions(WithClientConfig(cfg)),
+	}
+	return NewConnPool(address, cfg.MaxIdle, cfg.IdleTimeout, append(poolOpts, opts...)...)
 }
 
+// Get 获取连接，达到最大连接数时一直等待
 func (p *ConnPool) Get() (*Client, error) {
+	return p.GetContext(context.Background())
+}
+
+// GetContext 获取连接，达到最大连接数时等待其他调用方归还连接，直到ctx结束
+func (p *ConnPool) GetContext(ctx context.Context) (*Client, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
+
+	// 优先复用最近归还的空闲连接，丢弃过期或已失效的连接
+	for len(p.idle) > 0 {
+		ic := p.idle[len(p.idle)-1]
+		p.idle[len(p.idle)-1] = nil
+		p.idle = p.idle[:len(p.idle)-1]
+		if p.expired(ic) || !ic.client.available() {
+			p.open--
+			ic.client.Close()
+			continue
+		}
+		p.mu.Unlock()
+		return ic.client, nil
+	}
+
+	if p.maxActive <= 0 || p.open < p.maxActive {
+		p.open++
+		p.mu.Unlock()
+		return p.createConn()
+	}
+
+	// 达到最大连接数，排队等待
+	ch := make(chan *Client, 1)
+	p.waiters = append(p.waiters, ch)
+	p.waitCount++
+	start := time.Now()
+	p.mu.Unlock()
+
 	select {
-	case client := <-p.conns:
+	case client, ok := <-ch:
+		p.addWaitDuration(time.Since(start))
+		if !ok {
+			return nil, ErrPoolClosed
+		}
 		if client == nil {
 			return p.createConn()
 		}
 		return client, nil
-	default:
-		return p.createConn()
+	case <-ctx.Done():
+		p.addWaitDuration(time.Since(start))
+
+		p.mu.Lock()
+		removed := p.removeWaiter(ch)
+		p.mu.Unlock()
+
+		// 已经分配到连接，归还给连接池
+		if !removed {
+			if client, ok := <-ch; ok {
+				if client != nil {
+					p.Put(client)
+				} else {
+					p.mu.Lock()
+					p.release()
+					p.mu.Unlock()
+				}
+			}
+		}
+		return nil, contextError(ctx.Err())
 	}
 }
 
+// Put 归还连接，失效的连接会被关闭
 func (p *ConnPool) Put(client *Client) {
 	if client == nil {
 		return
 	}
 
-	select {
-	case p.conns <- client:
-		// 成功放回连接池
-	default:
-		// 连接池满了，直接关闭连接
+	p.mu.Lock()
+	if p.closed {
+		p.open--
+		p.mu.Unlock()
 		client.Close()
+		return
 	}
+
+	if !client.available() {
+		p.release()
+		p.mu.Unlock()
+		client.Close()
+		return
+	}
+
+	// 直接交给等待中的调用方
+	if len(p.waiters) > 0 {
+		ch := p.waiters[0]
+		p.waiters = p.waiters[1:]
+		ch <- client
+		p.mu.Unlock()
+		return
+	}
+
+	if len(p.idle) < p.maxIdle {
+		p.idle = append(p.idle, &idleConn{client: client, since: time.Now()})
+		p.mu.Unlock()
+		return
+	}
+
+	// 空闲连接已满，直接关闭连接
+	p.release()
+	p.mu.Unlock()
+	client.Close()
 }
 
-func (p *ConnPool) createConn() (*Client, error) {
-	return NewClient(p.address)
+// Stats 返回连接池统计信息
+func (p *ConnPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolStats{
+		Open:         p.open,
+		Idle:         len(p.idle),
+		InUse:        p.open - len(p.idle),
+		WaitCount:    p.waitCount,
+		WaitDuration: p.waitDuration,
+	}
 }
 
 func (p *ConnPool) Close() {
 	p.mu.Lock()
-	defer p.mu.Unlock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.stop)
+
+	idle := p.idle
+	p.idle = nil
+	p.open -= len(idle)
+	for _, ch := range p.waiters {
+		close(ch)
+	}
+	p.waiters = nil
+	p.mu.Unlock()
+
+	for _, ic := range idle {
+		ic.client.Close()
+	}
+}
+
+// createConn 新建连接，失败时释放已占用的连接名额
+func (p *ConnPool) createConn() (*Client, error) {
+	client, err := NewClient(p.address, p.clientOpts...)
+	if err != nil {
+		p.mu.Lock()
+		p.release()
+		p.mu.Unlock()
+		return nil, err
+	}
+	return client, nil
+}
+
+// release 释放一个连接名额，有调用方等待时转交给它新建连接，调用时需持有锁
+func (p *ConnPool) release() {
+	if len(p.waiters) > 0 && !p.closed {
+		ch := p.waiters[0]
+		p.waiters = p.waiters[1:]
+		ch <- nil
+		return
+	}
+	p.open--
+}
+
+// removeWaiter 移除等待队列中的调用方，已经被分配连接时返回false，调用时需持有锁
+func (p *ConnPool) removeWaiter(ch chan *Client) bool {
+	for i, w := range p.waiters {
+		if w == ch {
+			p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ConnPool) addWaitDuration(d time.Duration) {
+	p.mu.Lock()
+	p.waitDuration += d
+	p.mu.Unlock()
+}
+
+// expired 判断空闲连接是否超过空闲时间，调用时需持有锁
+func (p *ConnPool) expired(ic *idleConn) bool {
+	return p.idleTimeout > 0 && time.Since(ic.since) > p.idleTimeout
+}
+
+// cleaner 定期关闭超时或已失效的空闲连接
+func (p *ConnPool) cleaner() {
+	ticker := time.NewTicker(p.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		var evicted []*Client
+		p.mu.Lock()
+		kept := p.idle[:0]
+		for _, ic := range p.idle {
+			if p.expired(ic) || !ic.client.available() {
+				evicted = append(evicted, ic.client)
+				p.release()
+				continue
+			}
+			kept = append(kept, ic)
+		}
+		for i := len(kept); i < len(p.idle); i++ {
+			p.idle[i] = nil
+		}
+		p.idle = kept
+		p.mu.Unlock()
 
-	close(p.conns)
-	for client := range p.conns {
-		if client != nil {
+		for _, client := range evicted {
 			client.Close()
 		}
 	}
@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnPool(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.RegisterService(&BenchService{}))
+	addr := startTestServer(t, server)
+
+	t.Run("reuse idle connection", func(t *testing.T) {
+		pool := NewConnPool(addr, 2, time.Minute)
+		defer pool.Close()
+
+		client, err := pool.Get()
+		require.NoError(t, err)
+		assert.Equal(t, PoolStats{Open: 1, InUse: 1}, pool.Stats())
+
+		pool.Put(client)
+		assert.Equal(t, PoolStats{Open: 1, Idle: 1}, pool.Stats())
+
+		again, err := pool.Get()
+		require.NoError(t, err)
+		assert.Same(t, client, again)
+		pool.Put(again)
+	})
+
+	t.Run("max active and waiters", func(t *testing.T) {
+		pool := NewConnPool(addr, 1, time.Minute, WithMaxActive(1))
+		defer pool.Close()
+
+		client, err := pool.Get()
+		require.NoError(t, err)
+
+		// 达到上限后等待超时
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = pool.GetContext(ctx)
+		assert.ErrorIs(t, err, ErrTimeout)
+
+		// 归还的连接交给等待中的调用方
+		got := make(chan *Client, 1)
+		go func() {
+			c, err := pool.GetContext(context.Background())
+			assert.NoError(t, err)
+			got <- c
+		}()
+		assert.Eventually(t, func() bool {
+			return pool.Stats().WaitCount == 2
+		}, time.Second, 5*time.Millisecond)
+		pool.Put(client)
+
+		select {
+		case c := <-got:
+			assert.Same(t, client, c)
+			pool.Put(c)
+		case <-time.After(time.Second):
+			t.Fatal("waiter did not receive connection")
+		}
+
+		stats := pool.Stats()
+		assert.Equal(t, 1, stats.Open)
+		assert.Equal(t, int64(2), stats.WaitCount)
+		assert.Greater(t, stats.WaitDuration, time.Duration(0))
+	})
+
+	t.Run("idle timeout eviction", func(t *testing.T) {
+		pool := NewConnPool(addr, 2, 30*time.Millisecond)
+		defer pool.Close()
+
+		client, err := pool.Get()
+		require.NoError(t, err)
+		pool.Put(client)
+
+		assert.Eventually(t, func() bool {
+			return pool.Stats().Open == 0
+		}, time.Second, 5*time.Millisecond)
+		assert.False(t, client.available())
+	})
+
+	t.Run("broken connection eviction", func(t *testing.T) {
+		pool := NewConnPool(addr, 2, time.Minute, WithMaxActive(1))
+		defer pool.Close()
+
+		client, err := pool.Get()
+		require.NoError(t, err)
+		pool.Put(client)
+		client.Close()
+
+		fresh, err := pool.Get()
+		require.NoError(t, err)
+		assert.NotSame(t, client, fresh)
+		assert.Equal(t, 1, pool.Stats().Open)
+
+		// 归还已失效的连接时释放名额
+		fresh.Close()
+		pool.Put(fresh)
+		assert.Equal(t, PoolStats{}, pool.Stats())
+	})
+
+	t.Run("put after close", func(t *testing.T) {
+		pool := NewConnPool(addr, 2, time.Minute, WithMaxActive(1))
+		client, err := pool.Get()
+		require.NoError(t, err)
+
+		waitErr := make(chan error, 1)
+		go func() {
+			_, err := pool.Get()
+			waitErr <- err
+		}()
+		assert.Eventually(t, func() bool {
+			return pool.Stats().WaitCount == 1
+		}, time.Second, 5*time.Millisecond)
+
+		pool.Close()
+		pool.Close()
+		assert.ErrorIs(t, <-waitErr, ErrPoolClosed)
+
+		assert.NotPanics(t, func() { pool.Put(client) })
+		assert.False(t, client.available())
+
+		_, err = pool.Get()
+		assert.ErrorIs(t, err, ErrPoolClosed)
+	})
+
+	t.Run("from config", func(t *testing.T) {
+		cfg := config.NewClientConfig(config.WithPoolConfig(5, 1, time.Minute), config.WithConnectionLimit(2))
+		pool := NewConnPoolFromConfig(addr, cfg)
+		defer pool.Close()
+
+		assert.Equal(t, 2, pool.maxActive)
+		assert.Equal(t, 1, pool.maxIdle)
+
+		client, err := pool.Get()
+		require.NoError(t, err)
+		defer pool.Put(client)
+
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "pooled"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"pooled"}`, string(data))
+	})
+}
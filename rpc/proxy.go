@@ -2,7 +2,6 @@ package rpc
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"reflect"
 	"time"
@@ -10,15 +9,20 @@ import (
 	"github.com/fyerfyer/fyer-rpc/cluster/failover"
 	"github.com/fyerfyer/fyer-rpc/discovery"
 	"github.com/fyerfyer/fyer-rpc/naming"
+	"github.com/fyerfyer/fyer-rpc/protocol"
 )
 
 type Proxy struct {
-	pool            *ConnPool
-	loadBalancer    *discovery.LoadBalancer          // 负载均衡器
-	enableFailover  bool                             // 是否启用故障转移
-	failoverConfig  *failover.Config                 // 故障转移配置
-	serviceName     string                           // 服务名称
-	failoverHandler *failover.DefaultFailoverHandler // 故障转移处理器
+	pool              *ConnPool
+	loadBalancer      *discovery.LoadBalancer          // 负载均衡器
+	enableFailover    bool                             // 是否启用故障转移
+	failoverConfig    *failover.Config                 // 故障转移配置
+	serviceName       string                           // 服务名称
+	serviceVersion    string                           // 服务版本，为空时使用服务端的默认版本
+	failoverHandler   *failover.DefaultFailoverHandler // 故障转移处理器
+	serializationType uint8                            // 序列化类型
+	clientOpts        []ClientOption                   // 创建客户端时使用的选项
+	oneway            map[string]bool                  // 使用单向调用的方法
 }
 
 // ProxyOption 代理配置选项
@@ -51,6 +55,40 @@ func WithServiceName(serviceName string) ProxyOption {
 	}
 }
 
+// WithServiceVersion 设置调用的服务版本
+func WithServiceVersion(version string) ProxyOption {
+	return func(p *Proxy) {
+		p.serviceVersion = version
+	}
+}
+
+// WithProxySerialization 设置代理使用的序列化类型
+func WithProxySerialization(serializationType uint8) ProxyOption {
+	return func(p *Proxy) {
+		p.serializationType = serializationType
+	}
+}
+
+// WithProxyClientOptions 设置代理创建客户端时使用的选项
+func WithProxyClientOptions(opts ...ClientOption) ProxyOption {
+	return func(p *Proxy) {
+		p.clientOpts = append(p.clientOpts, opts...)
+	}
+}
+
+// WithOneway 将指定方法设置为单向调用，调用在请求发出后立即返回，不等待服务端处理
+// 这些方法的签名必须为 func(ctx context.Context, req *Req) error
+func WithOneway(methods ...string) ProxyOption {
+	return func(p *Proxy) {
+		if p.oneway == nil {
+			p.oneway = make(map[string]bool)
+		}
+		for _, method := range methods {
+			p.oneway[method] = true
+		}
+	}
+}
+
 // InitProxy 初始化服务代理
 func InitProxy(address string, target interface{}, opts ...ProxyOption) error {
 	// 验证target参数
@@ -64,13 +102,10 @@ func InitProxy(address string, target interface{}, opts ...ProxyOption) error {
 		return NewRPCError(ErrCodeInvalidParam, "target must be settable")
 	}
 
-	// 创建连接池
-	pool := NewConnPool(address, 10, 5*time.Minute)
-
 	// 创建代理
 	proxy := &Proxy{
-		pool:           pool,
-		enableFailover: false,
+		enableFailover:    false,
+		serializationType: protocol.SerializationTypeJSON,
 	}
 
 	// 应用配置选项
@@ -78,6 +113,16 @@ func InitProxy(address string, target interface{}, opts ...ProxyOption) error {
 		opt(proxy)
 	}
 
+	// 序列化类型放在最后，覆盖客户端选项中的设置
+	if protocol.GetCodecByType(proxy.serializationType) == nil {
+		return NewRPCError(ErrCodeInvalidParam, "unsupported serialization type")
+	}
+	proxy.clientOpts = append(proxy.clientOpts, WithSerialization(proxy.serializationType))
+
+	// 创建连接池
+	pool := NewConnPool(address, 10, 5*time.Minute, WithClientOptions(proxy.clientOpts...))
+	proxy.pool = pool
+
 	// 获取目标类型
 	targetElem := targetValue.Elem()
 	targetType := targetElem.Type()
@@ -94,6 +139,10 @@ func InitProxy(address string, target interface{}, opts ...ProxyOption) error {
 
 			// 检查字段是否为函数类型
 			if field.Type.Kind() == reflect.Func {
+				if proxy.oneway[field.Name] && !returnsOnlyError(field.Type) {
+					pool.Close()
+					return NewRPCError(ErrCodeInvalidParam, fmt.Sprintf("one-way method %s must return only error", field.Name))
+				}
 				// 创建代理函数
 				handleStructField(proxy, targetElem, field, serviceName, pool)
 			}
@@ -116,7 +165,25 @@ func handleStructField(proxy *Proxy, targetElem reflect.Value, field reflect.Str
 		// 方法名使用字段名
 		methodName := field.Name
 
-		var resp []byte
+		// 响应按请求使用的序列化类型解码到result，只返回error的方法丢弃响应内容
+		var result interface{} = new([]byte)
+		var resultValue reflect.Value
+		if !returnsOnlyError(field.Type) {
+			respType := field.Type.Out(0)
+			if respType.Kind() == reflect.Ptr {
+				resultValue = reflect.New(respType.Elem())
+			} else {
+				// 响应为值类型时解码到新建的指针，返回其指向的值
+				resultValue = reflect.New(respType)
+			}
+			result = resultValue.Interface()
+		}
+		call := func(client *Client) error {
+			if proxy.oneway[methodName] {
+				return client.Notify(ctx, serviceName, methodName, req, WithVersion(proxy.serviceVersion))
+			}
+			return client.Invoke(ctx, serviceName, methodName, req, result, WithVersion(proxy.serviceVersion))
+		}
 		var callErr error
 
 		// 使用负载均衡器进行调用
@@ -129,36 +196,41 @@ func handleStructField(proxy *Proxy, targetElem reflect.Value, field reflect.Str
 
 			// 使用故障转移机制
 			if proxy.enableFailover && proxy.failoverHandler != nil && len(instances) > 0 {
+				succeeded := false
+
 				// 定义调用操作
 				operation := func(ctx context.Context, instance *naming.Instance) error {
 					// 创建到具体实例的新连接
-					client, err := NewClient(instance.Address)
+					client, err := NewClient(instance.Address, proxy.clientOpts...)
 					if err != nil {
 						return err
 					}
 					defer client.Close()
 
 					// 执行RPC调用
-					resp, err = client.CallWithTimeout(ctx, serviceName, methodName, req)
-					return err
+					if err := call(client); err != nil {
+						return err
+					}
+					succeeded = true
+					return nil
 				}
 
 				// 执行带故障转移的调用
-				result, err := proxy.failoverHandler.Execute(ctx, instances, operation)
+				failoverResult, err := proxy.failoverHandler.Execute(ctx, instances, operation)
 				if err != nil {
 					return createErrorReturn(field.Type, fmt.Errorf("failover failed: %w", err))
 				}
 
-				// 如果故障转移成功但没有设置响应，重新从返回的实例获取响应
-				if result.Success && resp == nil {
-					client, err := NewClient(result.Instance.Address)
+				// 如果故障转移成功但没有得到响应，重新从返回的实例获取响应
+				if failoverResult.Success && !succeeded {
+					client, err := NewClient(failoverResult.Instance.Address, proxy.clientOpts...)
 					if err != nil {
 						return createErrorReturn(field.Type, fmt.Errorf("failed to connect to selected instance: %w", err))
 					}
 					defer client.Close()
 
-					resp, callErr = client.CallWithTimeout(ctx, serviceName, methodName, req)
-				} else if !result.Success {
+					callErr = call(client)
+				} else if !failoverResult.Success {
 					callErr = fmt.Errorf("no available instances after failover attempts")
 				}
 			} else {
@@ -169,7 +241,7 @@ func handleStructField(proxy *Proxy, targetElem reflect.Value, field reflect.Str
 				}
 
 				// 创建到选定实例的连接
-				client, err := NewClient(instance.Address)
+				client, err := NewClient(instance.Address, proxy.clientOpts...)
 				if err != nil {
 					return createErrorReturn(field.Type, fmt.Errorf("failed to connect to selected instance: %w", err))
 				}
@@ -177,37 +249,37 @@ func handleStructField(proxy *Proxy, targetElem reflect.Value, field reflect.Str
 
 				// 执行RPC调用
 				startTime := time.Now()
-				resp, callErr = client.CallWithTimeout(ctx, serviceName, methodName, req)
+				callErr = call(client)
 				duration := time.Since(startTime)
 
 				// 反馈调用结果
 				proxy.loadBalancer.Feedback(ctx, instance, duration.Milliseconds(), callErr)
 			}
 		} else {
-			// 从连接池获取连接
-			client, err := pool.Get()
+			// 从连接池获取连接，连接池已满时最多等待到调用的截止时间
+			client, err := pool.GetContext(ctx)
 			if err != nil {
 				return createErrorReturn(field.Type, err)
 			}
 			defer pool.Put(client)
 
 			// 直接调用原始地址
-			resp, callErr = client.CallWithTimeout(ctx, serviceName, methodName, req)
+			callErr = call(client)
 		}
 
 		if callErr != nil {
 			return createErrorReturn(field.Type, callErr)
 		}
 
-		// 解析响应
-		result := reflect.New(field.Type.Out(0).Elem()).Interface()
-		if err := json.Unmarshal(resp, result); err != nil {
-			return createErrorReturn(field.Type, fmt.Errorf("failed to decode response: %w", err))
-		}
-
 		// 返回结果和nil错误
+		if returnsOnlyError(field.Type) {
+			return []reflect.Value{reflect.Zero(field.Type.Out(0))}
+		}
+		if field.Type.Out(0).Kind() != reflect.Ptr {
+			resultValue = resultValue.Elem()
+		}
 		return []reflect.Value{
-			reflect.ValueOf(result),
+			resultValue,
 			reflect.Zero(field.Type.Out(1)),
 		}
 	})
@@ -216,8 +288,16 @@ func handleStructField(proxy *Proxy, targetElem reflect.Value, field reflect.Str
 	targetElem.FieldByName(field.Name).Set(proxyFunc)
 }
 
+// returnsOnlyError 判断代理方法是否只返回error
+func returnsOnlyError(methodType reflect.Type) bool {
+	return methodType.NumOut() == 1 && methodType.Out(0) == reflect.TypeOf((*error)(nil)).Elem()
+}
+
 // createErrorReturn 创建错误返回值
 func createErrorReturn(methodType reflect.Type, err error) []reflect.Value {
+	if returnsOnlyError(methodType) {
+		return []reflect.Value{reflect.ValueOf(&err).Elem()}
+	}
 	return []reflect.Value{
 		reflect.Zero(methodType.Out(0)),
 		reflect.ValueOf(&err).Elem(),
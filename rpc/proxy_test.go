@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/fyerfyer/fyer-rpc/rpc/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxySerialization(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.RegisterService(&testdata.TestUserServiceImpl{}))
+	require.NoError(t, server.RegisterService(&testdata.UserServiceImpl{}))
+	addr := startTestServer(t, server)
+
+	t.Run("protobuf proxy", func(t *testing.T) {
+		var service testdata.TestUserService
+		require.NoError(t, InitProxy(addr, &service, WithProxySerialization(protocol.SerializationTypeProtobuf)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		resp, err := service.GetTestById(ctx, &testdata.GetTestByIdReq{Id: 123})
+		require.NoError(t, err)
+		assert.Equal(t, int64(123), resp.GetUser().GetId())
+		assert.Equal(t, "test", resp.GetUser().GetName())
+	})
+
+	t.Run("protobuf with non-proto types", func(t *testing.T) {
+		var service testdata.UserService
+		require.NoError(t, InitProxy(addr, &service, WithProxySerialization(protocol.SerializationTypeProtobuf)))
+
+		_, err := service.GetById(context.Background(), &testdata.GetByIdReq{Id: 123})
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+
+	t.Run("unsupported serialization", func(t *testing.T) {
+		var service testdata.UserService
+		assert.Error(t, InitProxy(addr, &service, WithProxySerialization(0x7f)))
+	})
+
+	t.Run("per call serialization", func(t *testing.T) {
+		client, err := NewClient(addr)
+		require.NoError(t, err)
+		defer client.Close()
+
+		protoResp := &testdata.GetTestByIdResp{}
+		err = client.Invoke(context.Background(), "TestUserService", "GetTestById",
+			&testdata.GetTestByIdReq{Id: 123}, protoResp, WithCallSerialization(protocol.SerializationTypeProtobuf))
+		require.NoError(t, err)
+		assert.Equal(t, "test", protoResp.GetUser().GetName())
+
+		jsonResp := &testdata.GetByIdResp{}
+		err = client.Invoke(context.Background(), "UserService", "GetById", &testdata.GetByIdReq{Id: 123}, jsonResp)
+		require.NoError(t, err)
+		assert.Equal(t, "test", jsonResp.User.Name)
+	})
+}
+
+// CounterProxy CounterService代理
+type CounterProxy struct {
+	Hold func(ctx context.Context, req *SlowRequest) (*SlowResponse, error)
+}
+
+func TestProxyPoolExhausted(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.RegisterService(&CounterService{}))
+	addr := startTestServer(t, server)
+
+	// 连接池只有一个连接且已被占用
+	pool := NewConnPool(addr, 1, time.Minute, WithMaxActive(1))
+	defer pool.Close()
+	held, err := pool.Get()
+	require.NoError(t, err)
+	defer pool.Put(held)
+
+	var proxy CounterProxy
+	target := reflect.ValueOf(&proxy).Elem()
+	field, _ := target.Type().FieldByName("Hold")
+	handleStructField(&Proxy{}, target, field, "CounterService", pool)
+
+	// 等待连接时遵守调用的截止时间
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := proxy.Hold(ctx, &SlowRequest{})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrTimeout)
+	case <-time.After(2 * time.Second):
+		t.Fatal("proxy call ignored the context while waiting for a connection")
+	}
+}
@@ -0,0 +1,310 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fyerfyer/fyer-rpc/utils"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ReflectionServiceName 内置反射服务的名称
+const ReflectionServiceName = "Reflection"
+
+// 方法的调用方式
+const (
+	MethodKindUnary        = "unary"         // 一元调用
+	MethodKindServerStream = "server_stream" // 服务端流
+	MethodKindStream       = "stream"        // 客户端流或双向流
+)
+
+// ListServicesRequest 列出服务的请求
+type ListServicesRequest struct{}
+
+// ListServicesResponse 列出服务的响应
+type ListServicesResponse struct {
+	Services []*ServiceSchema `json:"services"`
+}
+
+// DescribeServiceRequest 查询服务描述的请求，Version为空时使用默认版本
+type DescribeServiceRequest struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// ServiceSchema 服务的描述
+type ServiceSchema struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Methods     []*MethodSchema   `json:"methods"`
+}
+
+// MethodSchema 方法的描述，流式方法的请求和响应在流上收发，客户端流和双向流没有Request
+type MethodSchema struct {
+	Name     string      `json:"name"`
+	Kind     string      `json:"kind"`
+	Request  *TypeSchema `json:"request,omitempty"`
+	Response *TypeSchema `json:"response,omitempty"`
+}
+
+// TypeSchema 请求或响应类型的描述
+// Go类型通过反射推导，protobuf消息通过消息描述符推导并设置ProtoName
+type TypeSchema struct {
+	Name      string         `json:"name"`                 // 类型名称
+	Kind      string         `json:"kind"`                 // struct、slice、map、string、int64等
+	ProtoName string         `json:"proto_name,omitempty"` // protobuf消息的完整名称
+	Fields    []*FieldSchema `json:"fields,omitempty"`     // 结构体或消息的字段
+	Key       *TypeSchema    `json:"key,omitempty"`        // 映射的键类型
+	Elem      *TypeSchema    `json:"elem,omitempty"`       // 切片、数组和映射的元素类型
+	// 序列化的FileDescriptorSet，包含消息所在文件及其依赖，只在顶层的protobuf消息上设置
+	// 调用方可以据此构造动态消息，以Protobuf序列化发起调用
+	Descriptor []byte `json:"descriptor,omitempty"`
+}
+
+// FieldSchema 字段的描述，Name为序列化后的字段名
+type FieldSchema struct {
+	Name string      `json:"name"`
+	Type *TypeSchema `json:"type"`
+}
+
+// WithoutReflection 不注册内置的反射服务
+func WithoutReflection() ServerOption {
+	return func(s *Server) {
+		s.disableReflection = true
+	}
+}
+
+// reflectionService 内置反射服务，描述服务器上注册的服务、方法和请求响应结构
+type reflectionService struct {
+	server *Server
+}
+
+// ListServices 列出所有已注册的服务
+func (r *reflectionService) ListServices(ctx context.Context, req *ListServicesRequest) (*ListServicesResponse, error) {
+	descs := r.server.Services()
+	resp := &ListServicesResponse{Services: make([]*ServiceSchema, 0, len(descs))}
+	for _, desc := range descs {
+		resp.Services = append(resp.Services, DescribeService(desc))
+	}
+	return resp, nil
+}
+
+// DescribeService 查询单个服务的描述
+func (r *reflectionService) DescribeService(ctx context.Context, req *DescribeServiceRequest) (*ServiceSchema, error) {
+	desc, err := r.server.lookupService(req.Name, req.Version)
+	if err != nil {
+		return nil, err
+	}
+	return DescribeService(desc), nil
+}
+
+// DescribeService 根据服务描述生成服务的结构描述
+func DescribeService(desc *ServiceDesc) *ServiceSchema {
+	schema := &ServiceSchema{
+		Name:    desc.ServiceName,
+		Version: desc.Version,
+		Methods: make([]*MethodSchema, 0, len(desc.Methods)),
+	}
+	if desc.Info != nil {
+		schema.Description = desc.Info.Description
+		schema.Metadata = desc.Info.Metadata
+	}
+
+	for _, method := range desc.Methods {
+		schema.Methods = append(schema.Methods, describeMethod(method))
+	}
+	sort.Slice(schema.Methods, func(i, j int) bool {
+		return schema.Methods[i].Name < schema.Methods[j].Name
+	})
+	return schema
+}
+
+func describeMethod(method reflect.Method) *MethodSchema {
+	schema := &MethodSchema{Name: method.Name}
+	if !utils.IsStreamMethod(method) {
+		schema.Kind = MethodKindUnary
+		schema.Request = DescribeType(utils.GetRequestType(method))
+		if respType := utils.GetResponseType(method); respType != nil {
+			schema.Response = DescribeType(respType)
+		}
+		return schema
+	}
+
+	if reqType := utils.GetStreamRequestType(method); reqType != nil {
+		schema.Kind = MethodKindServerStream
+		schema.Request = DescribeType(reqType)
+	} else {
+		schema.Kind = MethodKindStream
+	}
+	return schema
+}
+
+var protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+// DescribeType 描述类型的序列化结构，实现proto.Message的类型使用其消息描述符
+func DescribeType(typ reflect.Type) *TypeSchema {
+	schema := describeType(typ, make(map[reflect.Type]bool))
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if reflect.PointerTo(typ).Implements(protoMessageType) {
+		msg := reflect.New(typ).Interface().(proto.Message)
+		schema.Descriptor = fileDescriptorSet(msg.ProtoReflect().Descriptor().ParentFile())
+	}
+	return schema
+}
+
+// fileDescriptorSet 序列化文件描述符及其传递依赖，依赖排在引用它的文件之前
+func fileDescriptorSet(file protoreflect.FileDescriptor) []byte {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	var add func(protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	add(file)
+
+	data, err := proto.Marshal(set)
+	if err != nil {
+		utils.Warn("Failed to marshal descriptor of %s: %v", file.Path(), err)
+		return nil
+	}
+	return data
+}
+
+func describeType(typ reflect.Type, visiting map[reflect.Type]bool) *TypeSchema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if reflect.PointerTo(typ).Implements(protoMessageType) {
+		msg := reflect.New(typ).Interface().(proto.Message)
+		return describeMessage(msg.ProtoReflect().Descriptor(), make(map[protoreflect.FullName]bool))
+	}
+
+	schema := &TypeSchema{Name: typeName(typ), Kind: typ.Kind().String()}
+	switch typ.Kind() {
+	case reflect.Struct:
+		// 递归类型只展开一次
+		if visiting[typ] {
+			return schema
+		}
+		visiting[typ] = true
+		defer delete(visiting, typ)
+		schema.Fields = describeFields(typ, visiting)
+	case reflect.Slice, reflect.Array:
+		schema.Elem = describeType(typ.Elem(), visiting)
+	case reflect.Map:
+		schema.Key = describeType(typ.Key(), visiting)
+		schema.Elem = describeType(typ.Elem(), visiting)
+	}
+	return schema
+}
+
+// describeFields 按JSON序列化的规则列出结构体字段
+func describeFields(typ reflect.Type, visiting map[reflect.Type]bool) []*FieldSchema {
+	var fields []*FieldSchema
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fields = append(fields, &FieldSchema{Name: name, Type: describeType(field.Type, visiting)})
+	}
+	return fields
+}
+
+func typeName(typ reflect.Type) string {
+	if typ.Name() != "" && typ.PkgPath() != "" {
+		return fmt.Sprintf("%s.%s", typ.PkgPath(), typ.Name())
+	}
+	return typ.String()
+}
+
+// describeMessage 根据protobuf消息描述符描述消息结构
+func describeMessage(desc protoreflect.MessageDescriptor, visiting map[protoreflect.FullName]bool) *TypeSchema {
+	schema := &TypeSchema{
+		Name:      string(desc.Name()),
+		Kind:      reflect.Struct.String(),
+		ProtoName: string(desc.FullName()),
+	}
+	if visiting[desc.FullName()] {
+		return schema
+	}
+	visiting[desc.FullName()] = true
+	defer delete(visiting, desc.FullName())
+
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		schema.Fields = append(schema.Fields, &FieldSchema{
+			Name: field.JSONName(),
+			Type: describeField(field, visiting),
+		})
+	}
+	return schema
+}
+
+func describeField(field protoreflect.FieldDescriptor, visiting map[protoreflect.FullName]bool) *TypeSchema {
+	if field.IsMap() {
+		return &TypeSchema{
+			Name: fmt.Sprintf("map<%s, %s>", scalarName(field.MapKey()), scalarName(field.MapValue())),
+			Kind: reflect.Map.String(),
+			Key:  describeSingular(field.MapKey(), visiting),
+			Elem: describeSingular(field.MapValue(), visiting),
+		}
+	}
+	if field.IsList() {
+		return &TypeSchema{
+			Name: "repeated " + scalarName(field),
+			Kind: reflect.Slice.String(),
+			Elem: describeSingular(field, visiting),
+		}
+	}
+	return describeSingular(field, visiting)
+}
+
+func describeSingular(field protoreflect.FieldDescriptor, visiting map[protoreflect.FullName]bool) *TypeSchema {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return describeMessage(field.Message(), visiting)
+	case protoreflect.EnumKind:
+		return &TypeSchema{Name: string(field.Enum().FullName()), Kind: "enum"}
+	}
+	return &TypeSchema{Name: field.Kind().String(), Kind: field.Kind().String()}
+}
+
+func scalarName(field protoreflect.FieldDescriptor) string {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return string(field.Message().FullName())
+	case protoreflect.EnumKind:
+		return string(field.Enum().FullName())
+	}
+	return field.Kind().String()
+}
@@ -0,0 +1,114 @@
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// TreeNode 递归类型
+type TreeNode struct {
+	Value    int         `json:"value"`
+	Children []*TreeNode `json:"children,omitempty"`
+	secret   string
+	Ignored  string `json:"-"`
+}
+
+func TestReflection(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.RegisterService(&GeometryService{deleted: make(chan string, 1)}))
+	require.NoError(t, server.RegisterService(&LogService{}))
+	addr := startTestServer(t, server)
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	t.Run("list services", func(t *testing.T) {
+		var resp ListServicesResponse
+		require.NoError(t, client.Invoke(ctx, ReflectionServiceName, "ListServices", &ListServicesRequest{}, &resp))
+
+		var names []string
+		for _, svc := range resp.Services {
+			names = append(names, svc.Name)
+		}
+		assert.Equal(t, []string{"GeometryService", "LogService", ReflectionServiceName}, names)
+	})
+
+	t.Run("describe service", func(t *testing.T) {
+		var schema ServiceSchema
+		require.NoError(t, client.Invoke(ctx, ReflectionServiceName, "DescribeService", &DescribeServiceRequest{Name: "GeometryService"}, &schema))
+		assert.Equal(t, DefaultServiceVersion, schema.Version)
+		require.Len(t, schema.Methods, 4)
+
+		move := schema.Methods[2]
+		assert.Equal(t, "Move", move.Name)
+		assert.Equal(t, MethodKindUnary, move.Kind)
+		assert.Equal(t, "struct", move.Request.Kind)
+		require.Len(t, move.Request.Fields, 2)
+		assert.Equal(t, "X", move.Request.Fields[0].Name)
+		assert.Equal(t, "int", move.Request.Fields[0].Type.Kind)
+
+		index := schema.Methods[1]
+		assert.Equal(t, "slice", index.Request.Kind)
+		assert.Equal(t, "string", index.Request.Elem.Kind)
+		assert.Equal(t, "map", index.Response.Kind)
+		assert.Equal(t, "int", index.Response.Elem.Kind)
+
+		// 只返回error的方法没有响应
+		assert.Nil(t, schema.Methods[0].Response)
+	})
+
+	t.Run("stream methods", func(t *testing.T) {
+		var schema ServiceSchema
+		require.NoError(t, client.Invoke(ctx, ReflectionServiceName, "DescribeService", &DescribeServiceRequest{Name: "LogService"}, &schema))
+		kinds := make(map[string]string)
+		for _, method := range schema.Methods {
+			kinds[method.Name] = method.Kind
+			assert.Nil(t, method.Response)
+		}
+		assert.Equal(t, MethodKindServerStream, kinds["Tail"])
+		assert.Equal(t, MethodKindStream, kinds["Echo"])
+	})
+
+	t.Run("unknown service", func(t *testing.T) {
+		var schema ServiceSchema
+		err := client.Invoke(ctx, ReflectionServiceName, "DescribeService", &DescribeServiceRequest{Name: "Missing"}, &schema)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("without reflection", func(t *testing.T) {
+		assert.Empty(t, NewServer(WithoutReflection()).Services())
+	})
+}
+
+func TestDescribeType(t *testing.T) {
+	t.Run("recursive struct", func(t *testing.T) {
+		schema := DescribeType(reflect.TypeOf(&TreeNode{}))
+		assert.Equal(t, "github.com/fyerfyer/fyer-rpc/rpc.TreeNode", schema.Name)
+		require.Len(t, schema.Fields, 2)
+		assert.Equal(t, "value", schema.Fields[0].Name)
+
+		children := schema.Fields[1]
+		assert.Equal(t, "children", children.Name)
+		assert.Equal(t, "slice", children.Type.Kind)
+		assert.Equal(t, schema.Name, children.Type.Elem.Name)
+		assert.Empty(t, children.Type.Elem.Fields)
+	})
+
+	t.Run("protobuf message", func(t *testing.T) {
+		schema := DescribeType(reflect.TypeOf(&structpb.ListValue{}))
+		assert.Equal(t, "google.protobuf.ListValue", schema.ProtoName)
+		require.Len(t, schema.Fields, 1)
+		assert.Equal(t, "values", schema.Fields[0].Name)
+
+		values := schema.Fields[0].Type
+		assert.Equal(t, "slice", values.Kind)
+		assert.Equal(t, "google.protobuf.Value", values.Elem.ProtoName)
+	})
+}
@@ -2,172 +2,779 @@ package rpc
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"reflect"
+	"sync"
+	"time"
 
+	"github.com/fyerfyer/fyer-rpc/config"
 	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/fyerfyer/fyer-rpc/transport"
 	"github.com/fyerfyer/fyer-rpc/utils"
 )
 
+// ErrServerClosed 服务器关闭后Start返回的错误
+var ErrServerClosed = NewRPCError(ErrCodeUnavailable, "server closed")
+
+// defaultMaxPendingRequests 每个连接默认的最大排队请求数
+const defaultMaxPendingRequests = 1024
+
 type Server struct {
-	services          map[string]*ServiceDesc
+	services          *serviceRegistry
 	serializationType uint8
+	compressType      uint8         // 响应压缩类型
+	compressMinSize   int           // 启用压缩的最小消息体大小
+	workerPoolSize    int           // 工作协程数量
+	maxConcurrent     int           // 最大并发请求数
+	maxPending        int           // 每个连接等待工作协程的最大请求数
+	maxStreams        int           // 每个连接的最大并发流数，为0时不限制
+	shutdownTimeout   time.Duration // 优雅关闭等待处理中请求的最长时间
+	keepAliveTime     time.Duration // 连接空闲多久发送心跳，为0时不检测客户端存活
+	keepAliveCount    int           // 连续多少次心跳未响应时关闭连接
+	maxHeaderBytes    int           // 接受的最大请求元数据大小
+	maxRequestSize    int           // 接受的最大请求消息体大小
+	workers           *workerPool   // 请求处理协程池
+	interceptors      []ServerInterceptor
+	interceptor       ServerInterceptor // 组合后的拦截器链
+	tlsConfig         *tls.Config       // TLS配置，为nil时使用明文连接
+	authenticator     Authenticator     // 请求认证器，为nil时不认证
+	disableReflection bool              // 不注册内置的反射服务
+
+	mu           sync.Mutex
+	listener     net.Listener
+	conns        map[*serverConn]struct{} // 活跃连接
+	shuttingDown bool
 }
 
-func NewServer() *Server {
-	return &Server{
-		services: make(map[string]*ServiceDesc),
+// ServerOption 服务器配置选项
+type ServerOption func(*Server)
+
+// WithServerCompression 设置响应消息体的压缩类型，消息体小于minSize字节时不压缩
+// 请求的解压只取决于消息头中的压缩类型，与该配置无关
+func WithServerCompression(compressType uint8, minSize int) ServerOption {
+	return func(s *Server) {
+		s.compressType = compressType
+		s.compressMinSize = minSize
 	}
 }
 
-// SetSerializationType 设置服务器使用的序列化类型
-func (s *Server) SetSerializationType(serializationType uint8) {
-	s.serializationType = serializationType
+// WithWorkerPoolSize 设置处理请求的工作协程数量
+func WithWorkerPoolSize(size int) ServerOption {
+	return func(s *Server) {
+		if size > 0 {
+			s.workerPoolSize = size
+		}
+	}
 }
 
-// RegisterService 注册服务实例
-func (s *Server) RegisterService(service interface{}) error {
-	serviceValue := reflect.ValueOf(service)
-	if serviceValue.Kind() != reflect.Ptr {
-		return NewRPCError(ErrCodeInvalidParam, "service must be a pointer")
+// WithMaxConcurrent 设置最大并发请求数，超出的请求在连接的等待队列中排队
+func WithMaxConcurrent(maxConcurrent int) ServerOption {
+	return func(s *Server) {
+		if maxConcurrent > 0 {
+			s.maxConcurrent = maxConcurrent
+		}
 	}
+}
 
-	// 获取服务实现类型
-	serviceType := serviceValue.Type().Elem()
+// WithMaxPendingRequests 设置每个连接上等待工作协程的最大请求数
+// 协程池饱和时请求在连接的等待队列中排队，队列已满时返回ErrCodeResourceExhausted错误
+func WithMaxPendingRequests(maxPending int) ServerOption {
+	return func(s *Server) {
+		if maxPending > 0 {
+			s.maxPending = maxPending
+		}
+	}
+}
 
-	// 使用结构体的基础名称（去掉Impl后缀）作为服务名
-	serviceName := serviceType.Name()
-	if len(serviceName) > 4 && serviceName[len(serviceName)-4:] == "Impl" {
-		serviceName = serviceName[:len(serviceName)-4]
+// WithMaxConcurrentStreams 设置每个连接上的最大并发流数，为0时不限制
+// 超出的流被拒绝，客户端收到ErrCodeResourceExhausted错误
+func WithMaxConcurrentStreams(maxStreams int) ServerOption {
+	return func(s *Server) {
+		if maxStreams >= 0 {
+			s.maxStreams = maxStreams
+		}
 	}
+}
 
-	// 使用utils.GetServiceMethods替代手动遍历
-	methods, err := utils.GetServiceMethods(service)
-	if err != nil {
-		return NewRPCError(ErrCodeInvalidParam, fmt.Sprintf("invalid service: %v", err))
+// WithShutdownTimeout 设置优雅关闭时等待处理中请求的最长时间
+func WithShutdownTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		if timeout > 0 {
+			s.shutdownTimeout = timeout
+		}
+	}
+}
+
+// WithServerKeepAlive 设置检测客户端存活的心跳，连接空闲interval后发送心跳，
+// 连续maxMissed次未响应时关闭连接；interval为0时不检测
+// 心跳只发送给完成握手的客户端，未握手的旧版本客户端不检测
+func WithServerKeepAlive(interval time.Duration, maxMissed int) ServerOption {
+	return func(s *Server) {
+		s.keepAliveTime = interval
+		if maxMissed > 0 {
+			s.keepAliveCount = maxMissed
+		}
+	}
+}
+
+// WithServerMessageSizeLimit 设置接受的最大请求元数据和消息体大小，为0时不限制
+// 超限的请求被丢弃，并向客户端返回ErrCodeResourceExhausted错误
+func WithServerMessageSizeLimit(maxHeaderBytes, maxRequestSize int) ServerOption {
+	return func(s *Server) {
+		s.maxHeaderBytes = maxHeaderBytes
+		s.maxRequestSize = maxRequestSize
 	}
+}
 
-	// 如果没有找到有效的方法
-	if len(methods) == 0 {
-		return NewRPCError(ErrCodeInvalidParam, "service has no valid RPC methods")
+// WithServerTLSConfig 使用TLS接受连接，需要验证客户端证书时设置tlsConfig.ClientAuth
+// 可以使用NewServerTLSConfig根据证书文件创建支持证书热加载的配置
+func WithServerTLSConfig(tlsConfig *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.tlsConfig = tlsConfig
 	}
+}
 
-	s.services[serviceName] = &ServiceDesc{
-		ServiceName: serviceName,
-		Methods:     methods,
-		Instance:    service,
+// WithServerConfig 使用配置文件中的服务器配置
+func WithServerConfig(cfg *config.ServerConfig) ServerOption {
+	return func(s *Server) {
+		if cfg == nil {
+			return
+		}
+		WithWorkerPoolSize(cfg.WorkerPoolSize)(s)
+		WithMaxConcurrent(cfg.MaxConcurrent)(s)
+		WithMaxConcurrentStreams(cfg.MaxConcurrentStreams)(s)
+		WithShutdownTimeout(cfg.ShutdownTimeout)(s)
+		WithServerKeepAlive(cfg.KeepAliveTime, cfg.KeepAliveCount)(s)
+		WithServerMessageSizeLimit(cfg.MaxHeaderBytes, cfg.MaxRequestSize)(s)
+		if cfg.CommonConfig != nil {
+			s.serializationType = uint8(cfg.SerializationType)
+			WithServerCompression(uint8(cfg.CompressType), cfg.CompressMinSize)(s)
+		}
 	}
-	return nil
+}
+
+func NewServer(options ...ServerOption) *Server {
+	server := &Server{
+		services:        newServiceRegistry(),
+		workerPoolSize:  config.DefaultServerConfig.WorkerPoolSize,
+		maxConcurrent:   config.DefaultServerConfig.MaxConcurrent,
+		maxPending:      defaultMaxPendingRequests,
+		maxStreams:      config.DefaultServerConfig.MaxConcurrentStreams,
+		shutdownTimeout: config.DefaultServerConfig.ShutdownTimeout,
+		keepAliveTime:   config.DefaultServerConfig.KeepAliveTime,
+		keepAliveCount:  config.DefaultServerConfig.KeepAliveCount,
+		maxHeaderBytes:  config.DefaultServerConfig.MaxHeaderBytes,
+		maxRequestSize:  config.DefaultServerConfig.MaxRequestSize,
+		conns:           make(map[*serverConn]struct{}),
+	}
+
+	// 应用配置选项
+	for _, option := range options {
+		option(server)
+	}
+
+	server.workers = newWorkerPool(server.workerPoolSize, server.maxConcurrent)
+	server.interceptor = chainServerInterceptors(server.interceptors)
+	if !server.disableReflection {
+		server.RegisterName(ReflectionServiceName, "", &reflectionService{server: server},
+			WithDescription("lists registered services, their methods and request/response schemas"))
+	}
+	return server
+}
+
+// SetSerializationType 设置服务器使用的序列化类型
+func (s *Server) SetSerializationType(serializationType uint8) {
+	s.serializationType = serializationType
 }
 
 // handleConnection 处理每个客户端连接
 func (s *Server) handleConnection(conn net.Conn) {
-	defer conn.Close()
+	// 先完成TLS握手，以便将客户端证书附加到请求上下文
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		tlsConn.SetDeadline(time.Now().Add(defaultHandshakeTimeout))
+		if err := tlsConn.Handshake(); err != nil {
+			// 未发送任何数据就断开的连接（例如端口探测）不记录警告
+			if err == io.EOF {
+				utils.Debug("TLS handshake with %s failed: %v", conn.RemoteAddr(), err)
+			} else {
+				utils.Warn("TLS handshake with %s failed: %v", conn.RemoteAddr(), err)
+			}
+			conn.Close()
+			return
+		}
+		tlsConn.SetDeadline(time.Time{})
+	}
 
 	connection := NewConnection(conn)
+	connection.SetCompression(s.compressType, s.compressMinSize)
+	connection.SetMaxMessageSize(handshakeLimit(s.maxHeaderBytes), handshakeLimit(s.maxRequestSize))
 	defer connection.Close()
 
+	sc := newServerConn(connection, s.maxPending, s.maxStreams)
+	if !s.trackConn(sc) {
+		return
+	}
+	defer s.untrackConn(sc)
+	defer sc.cancelAll()
+
+	// 请求经由连接的分发协程提交到工作协程池，协程池饱和时读循环不会阻塞
+	go s.submitPending(sc)
+	defer close(sc.pending)
+
+	first := true
 	for {
-		// 读取请求消息
+		// 读取消息
 		message, err := connection.Read()
 		if err != nil {
-			if err != io.EOF {
-				// 只有非EOF错误才记录
+			// 超限的请求已被丢弃，返回错误后继续处理该连接上的其他请求
+			var tooLarge *protocol.FrameTooLargeError
+			if errors.As(err, &tooLarge) {
+				s.rejectRequest(sc, tooLarge)
+				continue
+			}
+			// 只有非EOF错误且不是关闭流程中主动断开的连接才记录
+			if err != io.EOF && !sc.isDraining() {
 				utils.Error("Failed to read message: %v", err)
 			}
 			return
 		}
 
-		// 查找服务
-		serviceDesc, ok := s.services[message.Metadata.ServiceName]
-		if !ok {
-			s.sendError(connection, message.Header.MessageID, fmt.Sprintf("service not found: %s", message.Metadata.ServiceName))
-			continue
+		// 连接的第一条消息为握手消息；未发送握手的旧版本客户端按协议版本1处理
+		if first {
+			first = false
+			connection.SetMaxMessageSize(s.maxHeaderBytes, s.maxRequestSize)
+			if message.Header.MessageType == protocol.TypeHandshake {
+				if !s.acceptHandshake(sc, message) {
+					return
+				}
+				// 连接空闲时检测客户端是否存活，及时回收半开连接
+				// 旧版本客户端不认识心跳消息，只对完成握手的连接发送
+				go connection.keepAlive(s.keepAliveTime, s.keepAliveCount)
+				continue
+			}
 		}
 
-		// 查找方法
-		method, ok := serviceDesc.Methods[message.Metadata.MethodName]
-		if !ok {
-			s.sendError(connection, message.Header.MessageID, fmt.Sprintf("method not found: %s", message.Metadata.MethodName))
-			continue
+		switch message.Header.MessageType {
+		case protocol.TypeRequest:
+			// 已通知客户端停止发送的连接上仍可能收到在途请求，直接拒绝
+			if sc.isDraining() {
+				s.sendError(connection, message.Header.MessageID, NewRPCError(ErrCodeUnavailable, "server is shutting down"))
+				continue
+			}
+
+			// 请求交给工作协程池处理，读循环可以继续接收后续请求和取消消息
+			// 根据客户端传递的截止时间创建请求上下文
+			messageID := message.Header.MessageID
+			ctx, cancel := requestContext(sc.getPeer(), message.Metadata)
+			sc.track(messageID, cancel)
+			done := func() {
+				sc.untrack(messageID)
+				cancel()
+			}
+
+			queued := sc.enqueue(pendingTask{
+				run: func() {
+					defer done()
+					s.handleRequest(ctx, sc.conn, message)
+				},
+				drop: func() {
+					done()
+					message.Release()
+					s.sendError(connection, messageID, NewRPCError(ErrCodeUnavailable, "server is shutting down"))
+				},
+			})
+			if !queued {
+				done()
+				message.Release()
+				s.sendError(connection, messageID, NewRPCError(ErrCodeResourceExhausted, "server busy: too many pending requests"))
+			}
+		case protocol.TypeOneway:
+			// 单向调用没有响应，关闭流程中直接丢弃
+			if sc.isDraining() {
+				utils.Debug("Dropping one-way call %d: server is shutting down", message.Header.MessageID)
+				message.Release()
+				continue
+			}
+
+			// 登记后优雅关闭会等待单向调用处理完成
+			messageID := message.Header.MessageID
+			ctx, cancel := requestContext(sc.getPeer(), message.Metadata)
+			sc.track(messageID, cancel)
+			done := func() {
+				sc.untrack(messageID)
+				cancel()
+			}
+
+			queued := sc.enqueue(pendingTask{
+				run: func() {
+					defer done()
+					s.handleOneway(ctx, message)
+				},
+				drop: func() {
+					done()
+					message.Release()
+				},
+			})
+			if !queued {
+				done()
+				message.Release()
+				utils.Warn("Dropping one-way call %d: server busy", messageID)
+			}
+		case protocol.TypeStreamOpen:
+			if sc.isDraining() {
+				s.closeStream(connection, message.Header.MessageID, NewRPCError(ErrCodeUnavailable, "server is shutting down"))
+				continue
+			}
+
+			ctx, cancel := requestContext(sc.getPeer(), message.Metadata)
+			stream := &serverStream{
+				ctx:               ctx,
+				conn:              connection,
+				messageID:         message.Header.MessageID,
+				serializationType: message.Header.SerializationType,
+				buf:               newStreamBuffer(),
+			}
+			if !sc.addStream(stream) {
+				cancel()
+				message.Release()
+				s.closeStream(connection, stream.messageID, NewRPCError(ErrCodeResourceExhausted, "too many concurrent streams"))
+				continue
+			}
+			sc.track(stream.messageID, cancel)
+
+			// 流的生命周期可能很长，使用独立的goroutine处理，不占用工作协程
+			// 每个连接上的流数量受maxStreams限制
+			go func() {
+				defer func() {
+					sc.removeStream(stream.messageID)
+					stream.buf.close(ErrStreamClosed)
+					sc.untrack(stream.messageID)
+					cancel()
+				}()
+				s.handleStream(stream, message.Metadata)
+			}()
+		case protocol.TypeStreamData, protocol.TypeStreamHalfClose:
+			sc.dispatchStream(message)
+		case protocol.TypeCancel:
+			sc.cancel(message.Header.MessageID)
+		case protocol.TypePing:
+			connection.pong(message.Header.MessageID)
+		case protocol.TypePong:
+			// 心跳响应只用于刷新连接的活跃时间
+		default:
+			utils.Warn("Ignoring message with unknown type: %d", message.Header.MessageType)
 		}
+	}
+}
 
-		// 解码参数
-		serializer := protocol.GetCodecByType(message.Header.SerializationType)
-		if serializer == nil {
-			s.sendError(connection, message.Header.MessageID, "unsupported serialization type")
-			continue
+// submitPending 依次将连接等待队列中的任务提交到工作协程池
+// 协程池饱和时只阻塞该协程，读循环仍可以及时处理心跳、取消和GoAway等控制消息
+func (s *Server) submitPending(sc *serverConn) {
+	for task := range sc.pending {
+		if !s.workers.submit(task.run) {
+			task.drop()
 		}
+	}
+}
 
-		// 创建请求参数实例
-		reqType := utils.GetRequestType(method)
-		reqArg := reflect.New(reqType).Interface()
+// rejectRequest 向发送超限消息的请求或流返回资源耗尽错误
+func (s *Server) rejectRequest(sc *serverConn, tooLarge *protocol.FrameTooLargeError) {
+	utils.Warn("Rejecting oversized message from %s: %v", sc.conn.conn.RemoteAddr(), tooLarge)
+	err := NewRPCError(ErrCodeResourceExhausted, "request too large: "+tooLarge.Error())
+	messageID := tooLarge.Header.MessageID
 
-		if err := serializer.Decode(message.Payload, reqArg); err != nil {
-			s.sendError(connection, message.Header.MessageID, fmt.Sprintf("failed to decode request: %v", err))
-			continue
+	switch tooLarge.Header.MessageType {
+	case protocol.TypeRequest:
+		s.sendError(sc.conn, messageID, err)
+	case protocol.TypeStreamOpen:
+		s.closeStream(sc.conn, messageID, err)
+	case protocol.TypeStreamData:
+		// 取消流的处理，处理器的结束状态会被丢弃，由这里返回错误
+		sc.cancel(messageID)
+		s.closeStream(sc.conn, messageID, err)
+	}
+}
+
+// trackConn 登记活跃连接，服务器关闭后返回false
+func (s *Server) trackConn(sc *serverConn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shuttingDown {
+		return false
+	}
+	s.conns[sc] = struct{}{}
+	return true
+}
+
+// untrackConn 移除已断开的连接
+func (s *Server) untrackConn(sc *serverConn) {
+	s.mu.Lock()
+	delete(s.conns, sc)
+	s.mu.Unlock()
+}
+
+// requestContext 根据请求元数据中的超时时间创建上下文
+// 请求元数据和客户端信息附加在上下文中，处理器可以通过IncomingMetadata和PeerFromContext获取
+func requestContext(peer *Peer, metadata *protocol.Metadata) (context.Context, context.CancelFunc) {
+	ctx := withPeer(context.Background(), peer)
+	if metadata != nil {
+		ctx = withIncomingMetadata(ctx, metadata)
+		if metadata.Timeout > 0 {
+			return context.WithTimeout(ctx, time.Duration(metadata.Timeout)*time.Millisecond)
 		}
+	}
+	return context.WithCancel(ctx)
+}
 
-		// 创建context
-		ctx := context.Background()
+// handleRequest 处理单个请求并写回响应
+func (s *Server) handleRequest(ctx context.Context, connection *Connection, message *protocol.Message) {
+	resp, err := s.dispatch(ctx, message)
 
-		// 调用方法
-		resp, err := utils.InvokeMethod(ctx, serviceDesc.Instance, method, reqArg)
+	// 客户端已超时或取消，不再写回响应
+	if ctx.Err() != nil {
+		utils.Debug("Dropping response for message %d: %v", message.Header.MessageID, ctx.Err())
+		return
+	}
+	if err != nil {
+		s.sendError(connection, message.Header.MessageID, err)
+		return
+	}
+
+	// 序列化响应，序列化类型已在dispatch中校验；只返回error的方法响应为空
+	var respData []byte
+	if resp != nil {
+		serializer := protocol.GetCodecByType(message.Header.SerializationType)
+		respData, err = serializer.Encode(resp)
 		if err != nil {
-			s.sendError(connection, message.Header.MessageID, fmt.Sprintf("method execution error: %v", err))
-			continue
+			s.sendError(connection, message.Header.MessageID, NewRPCError(ErrCodeInternal, fmt.Sprintf("failed to encode response: %v", err)))
+			return
 		}
+	}
 
-		// 序列化响应
-		respData, err := serializer.Encode(resp)
-		if err != nil {
-			s.sendError(connection, message.Header.MessageID, fmt.Sprintf("failed to encode response: %v", err))
-			continue
+	// 发送响应
+	err = connection.Write(
+		"",
+		"",
+		protocol.TypeResponse,
+		message.Header.SerializationType,
+		message.Header.MessageID,
+		nil,
+		respData,
+	)
+	if err != nil {
+		utils.Error("Failed to send response: %v", err)
+	}
+}
+
+// handleOneway 处理单向调用，不写回响应，失败时只记录日志
+func (s *Server) handleOneway(ctx context.Context, message *protocol.Message) {
+	if _, err := s.dispatch(ctx, message); err != nil {
+		if message.Metadata != nil {
+			utils.Warn("One-way call %s.%s failed: %v", message.Metadata.ServiceName, message.Metadata.MethodName, err)
+		} else {
+			utils.Warn("One-way call failed: %v", err)
 		}
+	}
+}
 
-		// 发送响应
-		err = connection.Write(
-			"",
-			"",
-			protocol.TypeResponse,
-			message.Header.SerializationType,
-			message.Header.MessageID,
-			nil,
-			respData,
-		)
-		if err != nil {
-			utils.Error("Failed to send response: %v", err)
+// dispatch 认证请求、解码参数并调用服务方法，返回方法的响应
+func (s *Server) dispatch(ctx context.Context, message *protocol.Message) (interface{}, error) {
+	ctx, handler, reqArg, err := s.decodeRequest(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+
+	// 调用方法，存在拦截器时经由拦截器链调用
+	var resp interface{}
+	if s.interceptor != nil {
+		info := &ServerInfo{
+			ServiceName: message.Metadata.ServiceName,
+			MethodName:  message.Metadata.MethodName,
+			Metadata:    message.Metadata,
+		}
+		resp, err = s.interceptor(ctx, info, reqArg, handler)
+	} else {
+		resp, err = handler(ctx, reqArg)
+	}
+
+	if err != nil {
+		// 处理器返回的RPCError保留错误码和详情，其余错误视为内部错误
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+			err = NewRPCError(ErrCodeInternal, fmt.Sprintf("method execution error: %v", err))
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// decodeRequest 认证请求、查找服务方法并解码参数，返回调用该方法的处理函数和参数
+// 返回时消息的缓冲区已归还，处理器执行期间不再占用
+func (s *Server) decodeRequest(ctx context.Context, message *protocol.Message) (context.Context, Handler, interface{}, error) {
+	defer message.Release()
+	if message.Metadata == nil {
+		return nil, nil, nil, NewRPCError(ErrCodeInvalidParam, "missing request metadata")
+	}
+
+	// 认证通过后才查找服务，未认证的调用方无法探测服务是否存在
+	ctx, err := s.authenticate(ctx, message.Metadata, message.Payload)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// 查找服务
+	serviceDesc, err := s.lookupService(message.Metadata.ServiceName, message.Metadata.Version)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// 查找方法
+	method, ok := serviceDesc.Methods[message.Metadata.MethodName]
+	if !ok {
+		return nil, nil, nil, NewRPCError(ErrCodeNotFound, fmt.Sprintf("method not found: %s", message.Metadata.MethodName))
+	}
+
+	// 流式方法只能通过流调用
+	if utils.IsStreamMethod(method) {
+		return nil, nil, nil, NewRPCError(ErrCodeInvalidParam, fmt.Sprintf("method %s is a stream method", message.Metadata.MethodName))
+	}
+
+	// 解码参数
+	serializer := protocol.GetCodecByType(message.Header.SerializationType)
+	if serializer == nil {
+		return nil, nil, nil, NewRPCError(ErrCodeInvalidParam, "unsupported serialization type")
+	}
+
+	// 创建请求参数实例
+	reqType := utils.GetRequestType(method)
+	reqArg := reflect.New(reqType).Interface()
+
+	if err := serializer.Decode(message.Payload, reqArg); err != nil {
+		return nil, nil, nil, NewRPCError(ErrCodeInvalidParam, fmt.Sprintf("failed to decode request: %v", err))
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return utils.InvokeMethod(ctx, serviceDesc.Instance, method, req)
+	}
+	return ctx, handler, reqArg, nil
+}
+
+// handleStream 处理流式调用，方法返回后向客户端发送结束消息
+func (s *Server) handleStream(stream *serverStream, metadata *protocol.Metadata) {
+	if metadata == nil {
+		s.closeStream(stream.conn, stream.messageID, NewRPCError(ErrCodeInvalidParam, "missing request metadata"))
+		return
+	}
+
+	ctx, err := s.authenticate(stream.ctx, metadata, nil)
+	if err != nil {
+		s.closeStream(stream.conn, stream.messageID, err)
+		return
+	}
+	stream.ctx = ctx
+
+	serviceDesc, err := s.lookupService(metadata.ServiceName, metadata.Version)
+	if err != nil {
+		s.closeStream(stream.conn, stream.messageID, err)
+		return
+	}
+	method, ok := serviceDesc.Methods[metadata.MethodName]
+	if !ok || !utils.IsStreamMethod(method) {
+		s.closeStream(stream.conn, stream.messageID, NewRPCError(ErrCodeNotFound, fmt.Sprintf("stream method not found: %s", metadata.MethodName)))
+		return
+	}
+	if protocol.GetCodecByType(stream.serializationType) == nil {
+		s.closeStream(stream.conn, stream.messageID, NewRPCError(ErrCodeInvalidParam, "unsupported serialization type"))
+		return
+	}
+
+	// 服务端流方法先读取客户端发送的第一条消息作为请求参数
+	var reqArg interface{}
+	if reqType := utils.GetStreamRequestType(method); reqType != nil {
+		reqArg = reflect.New(reqType).Interface()
+		if err := stream.RecvMsg(reqArg); err != nil {
+			if err == io.EOF {
+				err = NewRPCError(ErrCodeInvalidParam, "missing stream request")
+			}
+			s.closeStream(stream.conn, stream.messageID, err)
 			return
 		}
 	}
+
+	err = utils.InvokeStreamMethod(serviceDesc.Instance, method, reqArg, stream)
+
+	// 客户端已取消流，不再发送结束消息
+	if stream.ctx.Err() != nil {
+		utils.Debug("Dropping stream status for %s.%s: %v", metadata.ServiceName, metadata.MethodName, stream.ctx.Err())
+		return
+	}
+
+	if err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+			err = NewRPCError(ErrCodeInternal, fmt.Sprintf("method execution error: %v", err))
+		}
+	}
+	s.closeStream(stream.conn, stream.messageID, err)
 }
 
-func (s *Server) sendError(conn *Connection, messageID uint64, errMsg string) error {
+// closeStream 结束流，err为nil表示正常结束
+func (s *Server) closeStream(conn *Connection, messageID uint64, err error) {
+	var metadata *protocol.Metadata
+	if err != nil {
+		status := toStatus(err)
+		metadata = &protocol.Metadata{Error: status.Message, Status: status}
+	}
+	if err := conn.Write("", "", protocol.TypeStreamClose, protocol.SerializationTypeJSON, messageID, metadata, nil); err != nil {
+		utils.Error("Failed to close stream: %v", err)
+	}
+}
+
+func (s *Server) sendError(conn *Connection, messageID uint64, err error) error {
+	status := toStatus(err)
 	return conn.Write(
 		"",
 		"",
 		protocol.TypeResponse,
 		protocol.SerializationTypeJSON,
 		messageID,
-		&protocol.Metadata{Error: errMsg},
+		&protocol.Metadata{Error: status.Message, Status: status},
 		nil,
 	)
 }
 
+// Start 在address上监听并处理连接，直到服务器关闭
+// 地址的传输方式决定监听类型，例如unix:///run/svc.sock监听Unix域套接字，inproc://name监听进程内管道
 func (s *Server) Start(address string) error {
-	listener, err := net.Listen("tcp", address)
+	if s.compressType != protocol.CompressTypeNone && protocol.GetCompressorByType(s.compressType) == nil {
+		return NewRPCError(ErrCodeInvalidParam, "unsupported compress type")
+	}
+
+	listener, err := transport.Listen(address)
 	if err != nil {
-		return NewRPCError(ErrCodeInternal, "failed to start server: "+err.Error())
+		return WrapRPCError(ErrCodeInternal, "failed to start server", err)
+	}
+	return s.Serve(listener)
+}
+
+// Serve 在已创建的监听器上处理连接，直到服务器关闭或监听器被关闭，返回时关闭监听器
+func (s *Server) Serve(listener net.Listener) error {
+	if s.compressType != protocol.CompressTypeNone && protocol.GetCompressorByType(s.compressType) == nil {
+		listener.Close()
+		return NewRPCError(ErrCodeInvalidParam, "unsupported compress type")
+	}
+	if s.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.tlsConfig)
 	}
 	defer listener.Close()
 
+	s.mu.Lock()
+	if s.shuttingDown {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listener = listener
+	s.mu.Unlock()
+
+	s.workers.start()
+
 	for {
 		conn, err := listener.Accept()
 		if err != nil {
+			if s.isShuttingDown() {
+				return ErrServerClosed
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return WrapRPCError(ErrCodeUnavailable, "listener closed", err)
+			}
 			continue
 		}
 		go s.handleConnection(conn)
 	}
 }
+
+// isShuttingDown 判断服务器是否已开始关闭
+func (s *Server) isShuttingDown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shuttingDown
+}
+
+// Shutdown 优雅关闭服务器
+// 先关闭监听器并向所有连接发送GoAway，随后等待处理中的请求完成；
+// ctx未设置截止时间时最多等待shutdownTimeout，超时后强制关闭剩余连接
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shuttingDown = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	conns := make([]*serverConn, 0, len(s.conns))
+	for sc := range s.conns {
+		conns = append(conns, sc)
+	}
+	s.mu.Unlock()
+
+	// 通知客户端停止发送新请求
+	for _, sc := range conns {
+		if err := sc.goAway(); err != nil {
+			utils.Debug("Failed to send goaway: %v", err)
+		}
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.shutdownTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for {
+		if s.closeIdleConns() {
+			s.workers.stop()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			s.closeAllConns()
+			s.workers.stop()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// shutdownPollInterval 优雅关闭时检查连接状态的间隔
+const shutdownPollInterval = 10 * time.Millisecond
+
+// closeIdleConns 关闭没有处理中请求的连接，所有连接都已关闭时返回true
+func (s *Server) closeIdleConns() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sc := range s.conns {
+		if sc.inflight() == 0 {
+			sc.conn.Close()
+			delete(s.conns, sc)
+		}
+	}
+	return len(s.conns) == 0
+}
+
+// closeAllConns 强制关闭所有连接并取消处理中的请求
+func (s *Server) closeAllConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sc := range s.conns {
+		sc.cancelAll()
+		sc.conn.Close()
+		delete(s.conns, sc)
+	}
+}
@@ -0,0 +1,169 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+)
+
+// serverConn 服务端连接状态
+type serverConn struct {
+	conn       *Connection
+	mu         sync.Mutex
+	cancels    map[uint64]context.CancelFunc // 处理中的请求，用于响应客户端的取消消息
+	streams    map[uint64]*serverStream      // 进行中的流，用于投递客户端发送的流消息
+	maxStreams int                           // 最大并发流数，为0时不限制
+	draining   bool                          // 已发送GoAway，不再接受新请求
+	negotiated bool                          // 已完成握手，客户端能识别GoAway等控制消息
+	peer       *Peer                         // 客户端信息，附加到请求上下文中
+	pending    chan pendingTask              // 等待提交到工作协程池的任务
+}
+
+// pendingTask 等待提交到工作协程池的任务
+type pendingTask struct {
+	run  func()
+	drop func() // 协程池已停止、任务不会执行时调用
+}
+
+func newServerConn(conn *Connection, maxPending, maxStreams int) *serverConn {
+	peer := &Peer{Addr: conn.conn.RemoteAddr()}
+	if tlsConn, ok := conn.conn.(*tls.Conn); ok {
+		state := tlsConn.ConnectionState()
+		peer.TLS = &state
+	}
+	return &serverConn{
+		conn:       conn,
+		cancels:    make(map[uint64]context.CancelFunc),
+		streams:    make(map[uint64]*serverStream),
+		peer:       peer,
+		pending:    make(chan pendingTask, maxPending),
+		maxStreams: maxStreams,
+	}
+}
+
+// setPeer 记录握手时客户端声明的身份
+func (sc *serverConn) setPeer(handshake *protocol.Handshake) {
+	sc.mu.Lock()
+	peer := *sc.peer
+	peer.AppName = handshake.AppName
+	peer.InstanceID = handshake.InstanceID
+	sc.peer = &peer
+	sc.negotiated = true
+	sc.mu.Unlock()
+}
+
+// getPeer 返回客户端信息
+func (sc *serverConn) getPeer() *Peer {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.peer
+}
+
+// track 登记处理中的请求
+func (sc *serverConn) track(messageID uint64, cancel context.CancelFunc) {
+	sc.mu.Lock()
+	sc.cancels[messageID] = cancel
+	sc.mu.Unlock()
+}
+
+// untrack 移除处理完成的请求
+func (sc *serverConn) untrack(messageID uint64) {
+	sc.mu.Lock()
+	delete(sc.cancels, messageID)
+	sc.mu.Unlock()
+}
+
+// addStream 登记进行中的流，并发流数达到上限时返回false
+func (sc *serverConn) addStream(stream *serverStream) bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.maxStreams > 0 && len(sc.streams) >= sc.maxStreams {
+		return false
+	}
+	sc.streams[stream.messageID] = stream
+	return true
+}
+
+// removeStream 移除已结束的流
+func (sc *serverConn) removeStream(messageID uint64) {
+	sc.mu.Lock()
+	delete(sc.streams, messageID)
+	sc.mu.Unlock()
+}
+
+// dispatchStream 将客户端的流消息投递到对应的流，流已结束时丢弃
+func (sc *serverConn) dispatchStream(msg *protocol.Message) {
+	sc.mu.Lock()
+	stream, ok := sc.streams[msg.Header.MessageID]
+	sc.mu.Unlock()
+	if !ok {
+		msg.Release()
+		return
+	}
+	// 接收队列已满时等待流的处理器取出消息，读循环暂停读取，对客户端形成背压
+	stream.buf.put(msg, sc.conn.closed)
+}
+
+// cancel 取消指定请求
+func (sc *serverConn) cancel(messageID uint64) {
+	sc.mu.Lock()
+	cancel, ok := sc.cancels[messageID]
+	sc.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// enqueue 将任务放入等待队列，队列已满时返回false
+func (sc *serverConn) enqueue(task pendingTask) bool {
+	select {
+	case sc.pending <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+// cancelAll 连接断开时取消所有处理中的请求
+func (sc *serverConn) cancelAll() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for id, cancel := range sc.cancels {
+		cancel()
+		delete(sc.cancels, id)
+	}
+}
+
+// inflight 返回处理中的请求数量
+func (sc *serverConn) inflight() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return len(sc.cancels)
+}
+
+// isDraining 判断连接是否处于关闭流程中
+func (sc *serverConn) isDraining() bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.draining
+}
+
+// goAway 通知客户端停止在该连接上发送新请求
+// 未握手的旧版本客户端不识别GoAway，只将连接标记为关闭中
+func (sc *serverConn) goAway() error {
+	sc.mu.Lock()
+	if sc.draining {
+		sc.mu.Unlock()
+		return nil
+	}
+	sc.draining = true
+	negotiated := sc.negotiated
+	sc.mu.Unlock()
+
+	if !negotiated {
+		return nil
+	}
+	return sc.conn.Write("", "", protocol.TypeGoAway, protocol.SerializationTypeJSON, 0, nil, nil)
+}
@@ -0,0 +1,335 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SlowRequest struct {
+	Delay time.Duration
+}
+
+type SlowResponse struct {
+	HasDeadline bool
+}
+
+// SlowService 用于测试超时与取消的服务
+type SlowService struct {
+	done chan error
+}
+
+func (s *SlowService) Wait(ctx context.Context, req *SlowRequest) (*SlowResponse, error) {
+	_, hasDeadline := ctx.Deadline()
+	select {
+	case <-time.After(req.Delay):
+		s.done <- nil
+		return &SlowResponse{HasDeadline: hasDeadline}, nil
+	case <-ctx.Done():
+		s.done <- ctx.Err()
+		return nil, ctx.Err()
+	}
+}
+
+func newSlowService() *SlowService {
+	return &SlowService{done: make(chan error, 1)}
+}
+
+func TestServerDeadlinePropagation(t *testing.T) {
+	service := newSlowService()
+	server := NewServer()
+	require.NoError(t, server.RegisterService(service))
+	addr := startTestServer(t, server)
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	t.Run("deadline reaches handler", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		data, err := client.CallWithTimeout(ctx, "SlowService", "Wait", &SlowRequest{})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"HasDeadline":true}`, string(data))
+		require.NoError(t, <-service.done)
+	})
+
+	t.Run("handler stops after deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := client.CallWithTimeout(ctx, "SlowService", "Wait", &SlowRequest{Delay: 10 * time.Second})
+		var rpcErr *RPCError
+		require.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, ErrCodeTimeout, rpcErr.Code)
+
+		select {
+		case err := <-service.done:
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		case <-time.After(time.Second):
+			t.Fatal("handler was not stopped after deadline")
+		}
+	})
+
+	t.Run("cancel aborts handler", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		_, err := client.CallWithTimeout(ctx, "SlowService", "Wait", &SlowRequest{Delay: 10 * time.Second})
+		var rpcErr *RPCError
+		require.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, ErrCodeCanceled, rpcErr.Code)
+
+		select {
+		case err := <-service.done:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("handler was not canceled")
+		}
+	})
+}
+
+// CounterService 记录同时执行的请求数
+type CounterService struct {
+	active    int32
+	maxActive int32
+}
+
+func (s *CounterService) Hold(ctx context.Context, req *SlowRequest) (*SlowResponse, error) {
+	active := atomic.AddInt32(&s.active, 1)
+	defer atomic.AddInt32(&s.active, -1)
+
+	for {
+		current := atomic.LoadInt32(&s.maxActive)
+		if active <= current || atomic.CompareAndSwapInt32(&s.maxActive, current, active) {
+			break
+		}
+	}
+
+	time.Sleep(req.Delay)
+	return &SlowResponse{}, nil
+}
+
+func TestServerConcurrentRequests(t *testing.T) {
+	t.Run("slow request does not block others", func(t *testing.T) {
+		server := NewServer()
+		require.NoError(t, server.RegisterService(&CounterService{}))
+		require.NoError(t, server.RegisterService(&BenchService{}))
+		addr := startTestServer(t, server)
+
+		client, err := NewClient(addr)
+		require.NoError(t, err)
+		defer client.Close()
+
+		slowDone := make(chan error, 1)
+		go func() {
+			_, err := client.Call("CounterService", "Hold", &SlowRequest{Delay: time.Second})
+			slowDone <- err
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		start := time.Now()
+		_, err = client.Call("BenchService", "Echo", &BenchRequest{Value: "fast"})
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+		require.NoError(t, <-slowDone)
+	})
+
+	t.Run("max concurrent limit", func(t *testing.T) {
+		service := &CounterService{}
+		server := NewServer(WithWorkerPoolSize(8), WithMaxConcurrent(2))
+		require.NoError(t, server.RegisterService(service))
+		addr := startTestServer(t, server)
+
+		client, err := NewClient(addr)
+		require.NoError(t, err)
+		defer client.Close()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := client.Call("CounterService", "Hold", &SlowRequest{Delay: 20 * time.Millisecond})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.LessOrEqual(t, atomic.LoadInt32(&service.maxActive), int32(2))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&service.maxActive))
+	})
+}
+
+func TestServerSaturatedPool(t *testing.T) {
+	server := NewServer(WithWorkerPoolSize(1), WithMaxConcurrent(1), WithMaxPendingRequests(1))
+	require.NoError(t, server.RegisterService(&CounterService{}))
+	addr := startTestServer(t, server)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	proto := &protocol.DefaultProtocol{}
+	send := func(messageType uint8, id uint64, metadata *protocol.Metadata, payload []byte) {
+		msg := &protocol.Message{
+			Header: protocol.Header{
+				MagicNumber:       protocol.MagicNumber,
+				Version:           protocol.ProtocolVersion,
+				MessageType:       messageType,
+				SerializationType: protocol.SerializationTypeJSON,
+				MessageID:         id,
+			},
+			Metadata: metadata,
+			Payload:  payload,
+		}
+		require.NoError(t, proto.EncodeMessage(msg, conn))
+	}
+	hold := &protocol.Metadata{ServiceName: "CounterService", MethodName: "Hold"}
+	payload := []byte(`{"Delay":500000000}`)
+
+	// 第一个请求占用工作协程，第二个阻塞在分发协程中，第三个进入等待队列
+	for id := uint64(1); id <= 3; id++ {
+		send(protocol.TypeRequest, id, hold, payload)
+		time.Sleep(30 * time.Millisecond)
+	}
+	send(protocol.TypeRequest, 4, hold, payload)
+	send(protocol.TypePing, 5, nil, nil)
+
+	// 等待队列已满的请求被拒绝，心跳在处理中的请求完成前得到响应
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(300*time.Millisecond)))
+	busy, err := proto.DecodeMessage(conn)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), busy.Header.MessageID)
+	require.NotNil(t, busy.Metadata.Status)
+	assert.Equal(t, ErrCodeResourceExhausted, busy.Metadata.Status.Code)
+
+	pong, err := proto.DecodeMessage(conn)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TypePong, pong.Header.MessageType)
+	assert.Equal(t, uint64(5), pong.Header.MessageID)
+}
+
+func TestServerShutdown(t *testing.T) {
+	t.Run("drain in-flight requests", func(t *testing.T) {
+		server := NewServer()
+		require.NoError(t, server.RegisterService(&CounterService{}))
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		listener.Close()
+
+		startErr := make(chan error, 1)
+		go func() {
+			startErr <- server.Start(addr)
+		}()
+
+		var client *Client
+		require.Eventually(t, func() bool {
+			client, err = NewClient(addr)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+		defer client.Close()
+
+		callDone := make(chan error, 1)
+		go func() {
+			_, err := client.Call("CounterService", "Hold", &SlowRequest{Delay: 300 * time.Millisecond})
+			callDone <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		shutdownDone := make(chan error, 1)
+		go func() {
+			shutdownDone <- server.Shutdown(context.Background())
+		}()
+
+		// 收到GoAway后客户端拒绝发起新调用
+		require.Eventually(t, func() bool {
+			_, err := client.Call("CounterService", "Hold", &SlowRequest{})
+			return errors.Is(err, ErrDraining)
+		}, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, <-callDone)
+		require.NoError(t, <-shutdownDone)
+		assert.ErrorIs(t, <-startErr, ErrServerClosed)
+
+		_, err = NewClient(addr)
+		assert.Error(t, err)
+	})
+
+	t.Run("force close after timeout", func(t *testing.T) {
+		service := newSlowService()
+		server := NewServer()
+		require.NoError(t, server.RegisterService(service))
+		addr := startTestServer(t, server)
+
+		client, err := NewClient(addr)
+		require.NoError(t, err)
+		defer client.Close()
+
+		callDone := make(chan error, 1)
+		go func() {
+			_, err := client.Call("SlowService", "Wait", &SlowRequest{Delay: 10 * time.Second})
+			callDone <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, server.Shutdown(ctx), context.DeadlineExceeded)
+
+		select {
+		case err := <-callDone:
+			assert.Error(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("in-flight call was not terminated")
+		}
+		assert.ErrorIs(t, <-service.done, context.Canceled)
+	})
+}
+
+func TestMessageSizeLimit(t *testing.T) {
+	server := NewServer(WithServerMessageSizeLimit(1024, 64))
+	require.NoError(t, server.RegisterService(&BenchService{}))
+	addr := startTestServer(t, server)
+
+	t.Run("oversized request", func(t *testing.T) {
+		client, err := NewClient(addr)
+		require.NoError(t, err)
+		defer client.Close()
+
+		_, err = client.Call("BenchService", "Echo", &BenchRequest{Value: strings.Repeat("x", 128)})
+		assert.ErrorIs(t, err, ErrResourceExhausted)
+		assert.Contains(t, err.Error(), "payload size")
+
+		// 超限请求被丢弃后连接仍可继续使用
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "small"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"small"}`, string(data))
+	})
+
+	t.Run("oversized response", func(t *testing.T) {
+		client, err := NewClient(addr, WithMessageSizeLimit(1024, 16))
+		require.NoError(t, err)
+		defer client.Close()
+
+		_, err = client.Call("BenchService", "Echo", &BenchRequest{Value: strings.Repeat("y", 32)})
+		assert.ErrorIs(t, err, ErrResourceExhausted)
+
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "ok"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"ok"}`, string(data))
+	})
+}
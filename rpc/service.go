@@ -0,0 +1,268 @@
+package rpc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fyerfyer/fyer-rpc/utils"
+)
+
+// DefaultServiceVersion 未指定版本时注册的服务版本
+const DefaultServiceVersion = "1.0.0"
+
+// ServiceInfo 服务信息结构体，用于描述RPC服务
+type ServiceInfo struct {
+	Name        string            // 服务名称
+	Version     string            // 服务版本
+	Description string            // 服务描述
+	Metadata    map[string]string // 服务元数据
+}
+
+// Service RPC服务接口，服务实现该接口时使用其返回的名称和版本注册
+type Service interface {
+	// ServiceInfo 返回服务信息
+	ServiceInfo() *ServiceInfo
+}
+
+// RegisterOption 注册服务时的配置选项
+type RegisterOption func(*ServiceInfo)
+
+// WithDescription 设置服务描述
+func WithDescription(description string) RegisterOption {
+	return func(info *ServiceInfo) {
+		info.Description = description
+	}
+}
+
+// WithServiceMetadata 设置服务元数据
+func WithServiceMetadata(metadata map[string]string) RegisterOption {
+	return func(info *ServiceInfo) {
+		if info.Metadata == nil {
+			info.Metadata = make(map[string]string, len(metadata))
+		}
+		for k, v := range metadata {
+			info.Metadata[k] = v
+		}
+	}
+}
+
+// serviceRegistry 并发安全的服务注册表，按服务名和版本索引
+// 请求未指定版本时路由到该服务最早注册且仍存在的版本
+type serviceRegistry struct {
+	mu       sync.RWMutex
+	services map[string]map[string]*ServiceDesc // 服务名 -> 版本 -> 服务
+	versions map[string][]string                // 服务名 -> 按注册顺序排列的版本
+}
+
+func newServiceRegistry() *serviceRegistry {
+	return &serviceRegistry{
+		services: make(map[string]map[string]*ServiceDesc),
+		versions: make(map[string][]string),
+	}
+}
+
+// add 添加服务，同名同版本的服务已存在时返回错误
+func (r *serviceRegistry) add(desc *ServiceDesc) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, ok := r.services[desc.ServiceName]
+	if !ok {
+		versions = make(map[string]*ServiceDesc)
+		r.services[desc.ServiceName] = versions
+	}
+	if _, exists := versions[desc.Version]; exists {
+		return NewRPCError(ErrCodeInvalidParam, fmt.Sprintf("service %s version %s already registered", desc.ServiceName, desc.Version))
+	}
+	versions[desc.Version] = desc
+	r.versions[desc.ServiceName] = append(r.versions[desc.ServiceName], desc.Version)
+	return nil
+}
+
+// remove 移除服务，version为空时移除默认版本，不存在时返回false
+func (r *serviceRegistry) remove(name, version string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, ok := r.services[name]
+	if !ok {
+		return false
+	}
+	if version == "" {
+		version = r.versions[name][0]
+	}
+	if _, ok := versions[version]; !ok {
+		return false
+	}
+	delete(versions, version)
+
+	order := r.versions[name]
+	for i, v := range order {
+		if v == version {
+			r.versions[name] = append(order[:i:i], order[i+1:]...)
+			break
+		}
+	}
+	if len(versions) == 0 {
+		delete(r.services, name)
+		delete(r.versions, name)
+	}
+	return true
+}
+
+// lookup 查找服务，version为空时使用默认版本，即最早注册且仍存在的版本
+func (r *serviceRegistry) lookup(name, version string) (*ServiceDesc, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, ok := r.services[name]
+	if !ok {
+		return nil, false
+	}
+	if version == "" {
+		version = r.versions[name][0]
+	}
+	desc, ok := versions[version]
+	return desc, ok
+}
+
+// list 返回所有服务，按服务名和注册顺序排列
+func (r *serviceRegistry) list() []*ServiceDesc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.services))
+	for name := range r.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var descs []*ServiceDesc
+	for _, name := range names {
+		for _, version := range r.versions[name] {
+			descs = append(descs, r.services[name][version])
+		}
+	}
+	return descs
+}
+
+// RegisterService 注册服务实例
+// 服务实现Service接口时使用其返回的名称和版本，否则使用结构体名称（去掉Impl后缀）和默认版本
+func (s *Server) RegisterService(service interface{}) error {
+	return s.RegisterName("", "", service)
+}
+
+// RegisterName 以指定的名称和版本注册服务实例，服务器运行期间也可以注册
+// name或version为空时依次使用服务实现的ServiceInfo、结构体名称（去掉Impl后缀）和DefaultServiceVersion
+// 同一服务可以注册多个版本，请求通过WithVersion选择版本，未指定版本时使用最早注册的版本
+func (s *Server) RegisterName(name, version string, service interface{}, opts ...RegisterOption) error {
+	serviceValue := reflect.ValueOf(service)
+	if serviceValue.Kind() != reflect.Ptr {
+		return NewRPCError(ErrCodeInvalidParam, "service must be a pointer")
+	}
+
+	info := &ServiceInfo{}
+	if svc, ok := service.(Service); ok {
+		if declared := svc.ServiceInfo(); declared != nil {
+			*info = *declared
+			WithServiceMetadata(declared.Metadata)(info)
+		}
+	}
+	if name != "" {
+		info.Name = name
+	}
+	if version != "" {
+		info.Version = version
+	}
+	if info.Name == "" {
+		info.Name = strings.TrimSuffix(serviceValue.Type().Elem().Name(), "Impl")
+	}
+	if info.Version == "" {
+		info.Version = DefaultServiceVersion
+	}
+	for _, opt := range opts {
+		opt(info)
+	}
+	if info.Name == "" {
+		return NewRPCError(ErrCodeInvalidParam, "service name is required")
+	}
+
+	methods, rejected, err := utils.ScanServiceMethods(service)
+	if err != nil {
+		return NewRPCError(ErrCodeInvalidParam, fmt.Sprintf("invalid service: %v", err))
+	}
+	// ServiceInfo用于声明服务信息，不是RPC方法
+	if _, ok := service.(Service); ok {
+		delete(rejected, "ServiceInfo")
+	}
+	if len(methods) == 0 {
+		return NewRPCError(ErrCodeInvalidParam, "service has no valid RPC methods"+rejectedReasons(rejected))
+	}
+	for _, name := range sortedKeys(rejected) {
+		utils.Warn("Service %s: skipping method %s: %v", info.Name, name, rejected[name])
+	}
+
+	return s.services.add(&ServiceDesc{
+		ServiceName: info.Name,
+		Version:     info.Version,
+		Info:        info,
+		Methods:     methods,
+		Rejected:    rejected,
+		Instance:    service,
+	})
+}
+
+// rejectedReasons 按方法名排列被拒绝方法的原因
+func rejectedReasons(rejected map[string]error) string {
+	var b strings.Builder
+	for i, name := range sortedKeys(rejected) {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(rejected[name].Error())
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string]error) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Unregister 注销服务的指定版本，服务器运行期间也可以注销；处理中的请求不受影响
+// version为空时注销未指定版本的请求所路由到的版本，即最早注册且仍存在的版本
+func (s *Server) Unregister(name, version string) error {
+	if !s.services.remove(name, version) {
+		if version == "" {
+			return NewRPCError(ErrCodeNotFound, fmt.Sprintf("service not found: %s", name))
+		}
+		return NewRPCError(ErrCodeNotFound, fmt.Sprintf("service not found: %s version %s", name, version))
+	}
+	return nil
+}
+
+// Services 返回已注册的服务，按服务名排列，同名服务按注册顺序排列
+func (s *Server) Services() []*ServiceDesc {
+	return s.services.list()
+}
+
+// lookupService 按请求元数据中的服务名和版本查找服务
+func (s *Server) lookupService(name, version string) (*ServiceDesc, error) {
+	desc, ok := s.services.lookup(name, version)
+	if ok {
+		return desc, nil
+	}
+	if version != "" {
+		return nil, NewRPCError(ErrCodeNotFound, fmt.Sprintf("service not found: %s version %s", name, version))
+	}
+	return nil, NewRPCError(ErrCodeNotFound, fmt.Sprintf("service not found: %s", name))
+}
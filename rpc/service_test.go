@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// GreetRequest 问候请求
+type GreetRequest struct {
+	Name string
+}
+
+// GreetResponse 问候响应
+type GreetResponse struct {
+	Message string
+}
+
+// GreeterV1 第一个版本的问候服务
+type GreeterV1 struct{}
+
+func (s *GreeterV1) Greet(ctx context.Context, req *GreetRequest) (*GreetResponse, error) {
+	return &GreetResponse{Message: "hello " + req.Name}, nil
+}
+
+// GreeterV2 第二个版本的问候服务
+type GreeterV2 struct{}
+
+func (s *GreeterV2) Greet(ctx context.Context, req *GreetRequest) (*GreetResponse, error) {
+	return &GreetResponse{Message: "hi " + req.Name}, nil
+}
+
+// DescribedGreeter 通过ServiceInfo声明名称和版本的问候服务
+type DescribedGreeter struct {
+	GreeterV2
+}
+
+func (s *DescribedGreeter) ServiceInfo() *ServiceInfo {
+	return &ServiceInfo{Name: "Greeter", Version: "3.0.0", Description: "described greeter"}
+}
+
+func TestRegisterName(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.RegisterName("Greeter", "1.0.0", &GreeterV1{}))
+	require.NoError(t, server.RegisterName("Greeter", "2.0.0", &GreeterV2{}, WithDescription("second")))
+	addr := startTestServer(t, server)
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	greet := func(opts ...CallOption) (string, error) {
+		var resp GreetResponse
+		err := client.Invoke(context.Background(), "Greeter", "Greet", &GreetRequest{Name: "fyer"}, &resp, opts...)
+		return resp.Message, err
+	}
+
+	t.Run("route by version", func(t *testing.T) {
+		msg, err := greet(WithVersion("1.0.0"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello fyer", msg)
+
+		msg, err = greet(WithVersion("2.0.0"))
+		require.NoError(t, err)
+		assert.Equal(t, "hi fyer", msg)
+
+		_, err = greet(WithVersion("9.9.9"))
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("default version", func(t *testing.T) {
+		// 未指定版本时使用最早注册的版本
+		msg, err := greet()
+		require.NoError(t, err)
+		assert.Equal(t, "hello fyer", msg)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		err := server.RegisterName("Greeter", "1.0.0", &GreeterV2{})
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+
+	t.Run("service info", func(t *testing.T) {
+		require.NoError(t, server.RegisterService(&DescribedGreeter{}))
+		msg, err := greet(WithVersion("3.0.0"))
+		require.NoError(t, err)
+		assert.Equal(t, "hi fyer", msg)
+
+		// 内置的反射服务排在最后
+		services := server.Services()
+		require.Len(t, services, 4)
+		assert.Equal(t, "2.0.0", services[1].Version)
+		assert.Equal(t, "second", services[1].Info.Description)
+		assert.Equal(t, "described greeter", services[2].Info.Description)
+	})
+
+	t.Run("unregister at runtime", func(t *testing.T) {
+		require.NoError(t, server.Unregister("Greeter", "1.0.0"))
+		_, err := greet(WithVersion("1.0.0"))
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		// 默认版本顺延到下一个注册的版本
+		msg, err := greet()
+		require.NoError(t, err)
+		assert.Equal(t, "hi fyer", msg)
+
+		assert.ErrorIs(t, server.Unregister("Greeter", "1.0.0"), ErrNotFound)
+
+		// 未指定版本时注销默认版本，与未指定版本的请求含义一致
+		require.NoError(t, server.Unregister("Greeter", ""))
+		_, err = greet(WithVersion("2.0.0"))
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = greet(WithVersion("3.0.0"))
+		require.NoError(t, err)
+
+		require.NoError(t, server.Unregister("Greeter", ""))
+		_, err = greet()
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorIs(t, server.Unregister("Greeter", ""), ErrNotFound)
+	})
+
+	t.Run("concurrent registration", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = server.RegisterName("Greeter", "1.0.0", &GreeterV1{})
+				_, _ = greet()
+				_ = server.Unregister("Greeter", "1.0.0")
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,140 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/fyerfyer/fyer-rpc/protocol/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+// 按错误码匹配的错误，用于 errors.Is(err, rpc.ErrNotFound) 判断远程错误类型
+var (
+	ErrInternal          = &RPCError{Code: ErrCodeInternal}
+	ErrInvalidParam      = &RPCError{Code: ErrCodeInvalidParam}
+	ErrNotFound          = &RPCError{Code: ErrCodeNotFound}
+	ErrTimeout           = &RPCError{Code: ErrCodeTimeout}
+	ErrCanceled          = &RPCError{Code: ErrCodeCanceled}
+	ErrUnavailable       = &RPCError{Code: ErrCodeUnavailable}
+	ErrResourceExhausted = &RPCError{Code: ErrCodeResourceExhausted}
+	ErrUnauthenticated   = &RPCError{Code: ErrCodeUnauthenticated}
+)
+
+var codeTexts = map[int]string{
+	ErrCodeInternal:          "internal error",
+	ErrCodeInvalidParam:      "invalid parameter",
+	ErrCodeNotFound:          "not found",
+	ErrCodeTimeout:           "timeout",
+	ErrCodeCanceled:          "canceled",
+	ErrCodeUnavailable:       "unavailable",
+	ErrCodeResourceExhausted: "resource exhausted",
+	ErrCodeUnauthenticated:   "unauthenticated",
+}
+
+// CodeText 返回错误码的描述
+func CodeText(code int) string {
+	if text, ok := codeTexts[code]; ok {
+		return text
+	}
+	return fmt.Sprintf("error code %d", code)
+}
+
+// Code 获取错误的错误码，nil返回0，非RPCError视为内部错误
+func Code(err error) int {
+	if err == nil {
+		return 0
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code
+	}
+	return ErrCodeInternal
+}
+
+// AddDetail 附加一个错误详情
+// proto.Message 类型使用Protobuf序列化，其余类型使用JSON序列化
+func (e *RPCError) AddDetail(detail interface{}) error {
+	typeName, c := detailCodec(detail)
+	value, err := c.Encode(detail)
+	if err != nil {
+		return fmt.Errorf("failed to encode error detail: %w", err)
+	}
+
+	e.Details = append(e.Details, &protocol.ErrorDetail{
+		Type:  typeName,
+		Value: value,
+	})
+	return nil
+}
+
+// Detail 查找与v类型一致的第一个错误详情并解码到v，未找到时返回false
+func (e *RPCError) Detail(v interface{}) (bool, error) {
+	typeName, c := detailCodec(v)
+	for _, detail := range e.Details {
+		if detail.Type != typeName {
+			continue
+		}
+		if err := c.Decode(detail.Value, v); err != nil {
+			return false, fmt.Errorf("failed to decode error detail: %w", err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// detailCodec 返回错误详情的类型名和编解码器
+func detailCodec(v interface{}) (string, codec.Codec) {
+	if pm, ok := v.(proto.Message); ok {
+		return string(pm.ProtoReflect().Descriptor().FullName()), codec.GetCodec(codec.Protobuf)
+	}
+
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return "", codec.GetCodec(codec.JSON)
+	}
+	return typ.String(), codec.GetCodec(codec.JSON)
+}
+
+// toStatus 将处理器返回的错误转换为响应状态
+func toStatus(err error) *protocol.Status {
+	var rpcErr *RPCError
+	switch {
+	case errors.As(err, &rpcErr):
+	case errors.Is(err, context.DeadlineExceeded):
+		rpcErr = NewRPCError(ErrCodeTimeout, err.Error())
+	case errors.Is(err, context.Canceled):
+		rpcErr = NewRPCError(ErrCodeCanceled, err.Error())
+	default:
+		rpcErr = NewRPCError(ErrCodeInternal, err.Error())
+	}
+
+	return &protocol.Status{
+		Code:    rpcErr.Code,
+		Message: rpcErr.Error(),
+		Details: rpcErr.Details,
+	}
+}
+
+// fromMetadata 从响应元数据中还原错误，没有错误时返回nil
+func fromMetadata(metadata *protocol.Metadata) error {
+	if metadata == nil {
+		return nil
+	}
+	if metadata.Status != nil {
+		return &RPCError{
+			Code:    metadata.Status.Code,
+			Message: metadata.Status.Message,
+			Details: metadata.Status.Details,
+		}
+	}
+	if metadata.Error != "" {
+		return NewRPCError(ErrCodeInternal, metadata.Error)
+	}
+	return nil
+}
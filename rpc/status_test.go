@@ -0,0 +1,111 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fyerfyer/fyer-rpc/rpc/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type LookupRequest struct {
+	Key string
+}
+
+type LookupResponse struct {
+	Value string
+}
+
+// MissingKey 错误详情
+type MissingKey struct {
+	Key string
+}
+
+// StatusService 返回各类错误的测试服务
+type StatusService struct{}
+
+func (s *StatusService) Lookup(ctx context.Context, req *LookupRequest) (*LookupResponse, error) {
+	switch req.Key {
+	case "missing":
+		err := NewRPCError(ErrCodeNotFound, "key not found")
+		if detailErr := err.AddDetail(&MissingKey{Key: req.Key}); detailErr != nil {
+			return nil, detailErr
+		}
+		if detailErr := err.AddDetail(&testdata.TestUser{Id: 7, Name: "owner"}); detailErr != nil {
+			return nil, detailErr
+		}
+		return nil, err
+	case "invalid":
+		return nil, NewRPCError(ErrCodeInvalidParam, "key is invalid")
+	case "plain":
+		return nil, errors.New("something broke")
+	}
+	return &LookupResponse{Value: req.Key}, nil
+}
+
+func TestStatusAcrossTheWire(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.RegisterService(&StatusService{}))
+	addr := startTestServer(t, server)
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	t.Run("handler error keeps code and details", func(t *testing.T) {
+		_, err := client.Call("StatusService", "Lookup", &LookupRequest{Key: "missing"})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NotErrorIs(t, err, ErrInvalidParam)
+		assert.Equal(t, ErrCodeNotFound, Code(err))
+
+		var rpcErr *RPCError
+		require.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, "key not found", rpcErr.Message)
+
+		detail := &MissingKey{}
+		found, err := rpcErr.Detail(detail)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, "missing", detail.Key)
+
+		user := &testdata.TestUser{}
+		found, err = rpcErr.Detail(user)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, int64(7), user.Id)
+		assert.Equal(t, "owner", user.Name)
+	})
+
+	t.Run("invalid param", func(t *testing.T) {
+		_, err := client.Call("StatusService", "Lookup", &LookupRequest{Key: "invalid"})
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+
+	t.Run("plain error becomes internal", func(t *testing.T) {
+		_, err := client.Call("StatusService", "Lookup", &LookupRequest{Key: "plain"})
+		assert.ErrorIs(t, err, ErrInternal)
+		assert.Contains(t, err.Error(), "something broke")
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		_, err := client.Call("StatusService", "Missing", &LookupRequest{})
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, err = client.Call("MissingService", "Lookup", &LookupRequest{})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestWrapRPCError(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := WrapRPCError(ErrCodeUnavailable, "failed to connect", cause)
+
+	assert.ErrorIs(t, err, cause)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, "failed to connect: connection refused", err.Error())
+	assert.Equal(t, "unavailable", ErrUnavailable.Error())
+	assert.Equal(t, 0, Code(nil))
+}
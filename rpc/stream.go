@@ -0,0 +1,310 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+)
+
+// ErrStreamClosed 在已结束的流上发送消息时返回的错误
+var ErrStreamClosed = NewRPCError(ErrCodeCanceled, "stream is closed")
+
+// ServerStream 服务端流，流式方法通过它收发消息
+// 服务端流方法签名为 func(req *Request, stream ServerStream) error，
+// 客户端流和双向流方法签名为 func(stream ServerStream) error，方法返回即结束流
+type ServerStream interface {
+	// Context 返回流的上下文，客户端取消或超时后上下文结束
+	Context() context.Context
+	// SendMsg 向客户端发送一条消息
+	SendMsg(m interface{}) error
+	// RecvMsg 接收客户端的下一条消息，客户端调用CloseSend后返回io.EOF
+	RecvMsg(m interface{}) error
+}
+
+// ClientStream 客户端流
+// Send和Recv可以分别在两个goroutine中调用，但同一个方法不能被并发调用
+// 接收队列已满时连接的读循环会等待Recv取出消息，不再读取的流应及时取消上下文
+type ClientStream interface {
+	// Context 返回流的上下文
+	Context() context.Context
+	// Send 向服务端发送一条消息，流已结束时返回io.EOF，可通过Recv获取最终状态
+	Send(m interface{}) error
+	// Recv 接收服务端的下一条消息，服务端正常结束流时返回io.EOF
+	Recv(m interface{}) error
+	// CloseSend 通知服务端不再发送消息
+	CloseSend() error
+}
+
+// streamBufferSize 每个流接收队列最多缓存的消息数
+const streamBufferSize = 128
+
+// streamBuffer 流消息接收队列，容量有限
+// 队列已满时读循环等待消费者取出消息，发送方随之受到TCP的背压，避免慢消费的流无限占用内存
+// 队列只支持单个生产者和单个消费者
+type streamBuffer struct {
+	mu     sync.Mutex
+	msgs   []*protocol.Message
+	err    error         // 队列终止原因，取完剩余消息后返回
+	notify chan struct{} // 有新消息或队列终止时发出通知
+	space  chan struct{} // 消息被取出或队列终止时发出通知
+}
+
+func newStreamBuffer() *streamBuffer {
+	return &streamBuffer{
+		notify: make(chan struct{}, 1),
+		space:  make(chan struct{}, 1),
+	}
+}
+
+// put 追加一条消息，队列已满时等待，直到有空间、队列终止或done关闭
+// 消息未能放入队列时丢弃并返回false
+func (b *streamBuffer) put(msg *protocol.Message, done <-chan struct{}) bool {
+	for {
+		b.mu.Lock()
+		if b.err != nil {
+			b.mu.Unlock()
+			msg.Release()
+			return false
+		}
+		if len(b.msgs) < streamBufferSize {
+			b.msgs = append(b.msgs, msg)
+			b.mu.Unlock()
+			wake(b.notify)
+			return true
+		}
+		b.mu.Unlock()
+
+		select {
+		case <-b.space:
+		case <-done:
+			msg.Release()
+			return false
+		}
+	}
+}
+
+// close 终止队列，之后的get在取完剩余消息后返回err，等待中的put返回false
+func (b *streamBuffer) close(err error) {
+	b.mu.Lock()
+	if b.err == nil {
+		b.err = err
+	}
+	b.mu.Unlock()
+	wake(b.notify)
+	wake(b.space)
+}
+
+// wake 发出不阻塞的通知，已有未处理的通知时忽略
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// get 取出下一条消息，队列为空时等待
+func (b *streamBuffer) get(ctx context.Context) (*protocol.Message, error) {
+	for {
+		b.mu.Lock()
+		if len(b.msgs) > 0 {
+			msg := b.msgs[0]
+			b.msgs[0] = nil
+			b.msgs = b.msgs[1:]
+			b.mu.Unlock()
+			wake(b.space)
+			return msg, nil
+		}
+		err := b.err
+		b.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-b.notify:
+		case <-ctx.Done():
+			return nil, contextError(ctx.Err())
+		}
+	}
+}
+
+// serverStream ServerStream的实现
+type serverStream struct {
+	ctx               context.Context
+	conn              *Connection
+	messageID         uint64
+	serializationType uint8
+	buf               *streamBuffer
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	if err := s.ctx.Err(); err != nil {
+		return contextError(err)
+	}
+
+	data, err := protocol.GetCodecByType(s.serializationType).Encode(m)
+	if err != nil {
+		return WrapRPCError(ErrCodeInternal, "failed to encode stream message", err)
+	}
+	return s.conn.Write("", "", protocol.TypeStreamData, s.serializationType, s.messageID, nil, data)
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	msg, err := s.buf.get(s.ctx)
+	if err != nil {
+		return err
+	}
+	if msg.Header.MessageType == protocol.TypeStreamHalfClose {
+		// 客户端不再发送，之后的RecvMsg都返回io.EOF
+		s.buf.close(io.EOF)
+		return io.EOF
+	}
+	return decodeStreamMessage(msg, m)
+}
+
+// clientStream ClientStream的实现
+type clientStream struct {
+	ctx               context.Context
+	client            *Client
+	messageID         uint64
+	serializationType uint8
+	buf               *streamBuffer
+
+	mu        sync.Mutex
+	sendDone  bool          // 已调用CloseSend
+	finished  bool          // 流已结束
+	done      chan struct{} // 流结束时关闭
+	finishErr error         // 流结束后Recv返回的错误，正常结束为io.EOF
+}
+
+func (cs *clientStream) Context() context.Context {
+	return cs.ctx
+}
+
+func (cs *clientStream) Send(m interface{}) error {
+	cs.mu.Lock()
+	sendDone, finished := cs.sendDone, cs.finished
+	cs.mu.Unlock()
+	if sendDone {
+		return ErrStreamClosed
+	}
+	if finished {
+		return io.EOF
+	}
+
+	data, err := protocol.GetCodecByType(cs.serializationType).Encode(m)
+	if err != nil {
+		return WrapRPCError(ErrCodeInvalidParam, "failed to encode stream message", err)
+	}
+	if err := cs.client.conn.Write("", "", protocol.TypeStreamData, cs.serializationType, cs.messageID, nil, data); err != nil {
+		return WrapRPCError(ErrCodeUnavailable, "failed to send stream message", err)
+	}
+	return nil
+}
+
+func (cs *clientStream) Recv(m interface{}) error {
+	msg, err := cs.buf.get(cs.ctx)
+	if err != nil {
+		return cs.result(err)
+	}
+
+	if msg.Header.MessageType == protocol.TypeStreamClose {
+		err := streamStatus(msg)
+		cs.finish(err)
+		cs.buf.close(err)
+		return cs.result(err)
+	}
+	return decodeStreamMessage(msg, m)
+}
+
+func (cs *clientStream) CloseSend() error {
+	cs.mu.Lock()
+	if cs.sendDone || cs.finished {
+		cs.mu.Unlock()
+		return nil
+	}
+	cs.sendDone = true
+	cs.mu.Unlock()
+
+	if err := cs.client.conn.Write("", "", protocol.TypeStreamHalfClose, cs.serializationType, cs.messageID, nil, nil); err != nil {
+		return WrapRPCError(ErrCodeUnavailable, "failed to close send", err)
+	}
+	return nil
+}
+
+// watch 上下文结束时取消流
+func (cs *clientStream) watch() {
+	select {
+	case <-cs.ctx.Done():
+		cs.abort(contextError(cs.ctx.Err()))
+	case <-cs.done:
+	}
+}
+
+// abort 放弃流，通知服务端取消处理
+func (cs *clientStream) abort(err error) {
+	if !cs.finish(err) {
+		return
+	}
+	cs.client.removeStream(cs.messageID)
+	cs.client.cancel(cs.messageID)
+	cs.buf.close(err)
+}
+
+// fail 连接断开时终止流
+func (cs *clientStream) fail(err error) {
+	if cs.finish(err) {
+		cs.buf.close(err)
+	}
+}
+
+// finish 标记流结束，首次调用时返回true
+func (cs *clientStream) finish(err error) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.finished {
+		return false
+	}
+	cs.finished = true
+	cs.finishErr = err
+	close(cs.done)
+	return true
+}
+
+// result 流结束后统一返回首次记录的结束原因
+func (cs *clientStream) result(err error) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.finished {
+		return cs.finishErr
+	}
+	return err
+}
+
+// streamStatus 返回流结束消息携带的最终状态，正常结束为io.EOF
+func streamStatus(msg *protocol.Message) error {
+	if err := fromMetadata(msg.Metadata); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// decodeStreamMessage 按消息头中的序列化类型解码流消息
+// 解码后消息的缓冲区被归还
+func decodeStreamMessage(msg *protocol.Message, m interface{}) error {
+	defer msg.Release()
+	serializer := protocol.GetCodecByType(msg.Header.SerializationType)
+	if serializer == nil {
+		return NewRPCError(ErrCodeInvalidParam, "unsupported serialization type")
+	}
+	if err := serializer.Decode(msg.Payload, m); err != nil {
+		return WrapRPCError(ErrCodeInvalidParam, "failed to decode stream message", err)
+	}
+	return nil
+}
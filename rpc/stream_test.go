@@ -0,0 +1,293 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TailRequest 服务端流请求
+type TailRequest struct {
+	Lines int
+}
+
+// LogLine 流消息
+type LogLine struct {
+	Text string
+}
+
+// LogSummary 客户端流的汇总结果
+type LogSummary struct {
+	Count int
+}
+
+// LogService 流式测试服务
+type LogService struct {
+	canceled chan struct{}
+}
+
+// Tail 服务端流：按请求返回指定行数
+func (s *LogService) Tail(req *TailRequest, stream ServerStream) error {
+	for i := 0; i < req.Lines; i++ {
+		if err := stream.SendMsg(&LogLine{Text: fmt.Sprintf("line-%d", i)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count 客户端流：统计收到的行数
+func (s *LogService) Count(stream ServerStream) error {
+	count := 0
+	for {
+		line := &LogLine{}
+		err := stream.RecvMsg(line)
+		if err == io.EOF {
+			return stream.SendMsg(&LogSummary{Count: count})
+		}
+		if err != nil {
+			return err
+		}
+		count++
+	}
+}
+
+// Echo 双向流：原样返回收到的每一行
+func (s *LogService) Echo(stream ServerStream) error {
+	for {
+		line := &LogLine{}
+		err := stream.RecvMsg(line)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.SendMsg(line); err != nil {
+			return err
+		}
+	}
+}
+
+// Fail 返回错误结束流
+func (s *LogService) Fail(req *TailRequest, stream ServerStream) error {
+	if err := stream.SendMsg(&LogLine{Text: "partial"}); err != nil {
+		return err
+	}
+	return NewRPCError(ErrCodeInvalidParam, "bad request")
+}
+
+// Follow 持续发送直到客户端取消
+func (s *LogService) Follow(req *TailRequest, stream ServerStream) error {
+	defer close(s.canceled)
+	for {
+		if err := stream.SendMsg(&LogLine{Text: "tick"}); err != nil {
+			return err
+		}
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	service := &LogService{canceled: make(chan struct{})}
+	server := NewServer()
+	require.NoError(t, server.RegisterService(service))
+	require.NoError(t, server.RegisterService(&BenchService{}))
+	addr := startTestServer(t, server)
+
+	client, err := NewClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	t.Run("server streaming", func(t *testing.T) {
+		stream, err := client.NewStream(context.Background(), "LogService", "Tail")
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&TailRequest{Lines: 3}))
+		require.NoError(t, stream.CloseSend())
+
+		var lines []string
+		for {
+			line := &LogLine{}
+			err := stream.Recv(line)
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			lines = append(lines, line.Text)
+		}
+		assert.Equal(t, []string{"line-0", "line-1", "line-2"}, lines)
+		assert.Equal(t, io.EOF, stream.Recv(&LogLine{}))
+	})
+
+	t.Run("client streaming", func(t *testing.T) {
+		stream, err := client.NewStream(context.Background(), "LogService", "Count")
+		require.NoError(t, err)
+		for i := 0; i < 5; i++ {
+			require.NoError(t, stream.Send(&LogLine{Text: "x"}))
+		}
+		require.NoError(t, stream.CloseSend())
+		assert.ErrorIs(t, stream.Send(&LogLine{}), ErrStreamClosed)
+
+		summary := &LogSummary{}
+		require.NoError(t, stream.Recv(summary))
+		assert.Equal(t, 5, summary.Count)
+		assert.Equal(t, io.EOF, stream.Recv(summary))
+	})
+
+	t.Run("finished without reading close", func(t *testing.T) {
+		stream, err := client.NewStream(context.Background(), "LogService", "Count")
+		require.NoError(t, err)
+		require.NoError(t, stream.CloseSend())
+		require.NoError(t, stream.Recv(&LogSummary{}))
+
+		// 调用方不再读取结束消息时，流也随服务端的结束消息结束
+		select {
+		case <-stream.(*clientStream).done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("stream was not finished by the close message")
+		}
+		assert.Equal(t, io.EOF, stream.Recv(&LogSummary{}))
+	})
+
+	t.Run("bidirectional", func(t *testing.T) {
+		stream, err := client.NewStream(context.Background(), "LogService", "Echo")
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			text := fmt.Sprintf("msg-%d", i)
+			require.NoError(t, stream.Send(&LogLine{Text: text}))
+			line := &LogLine{}
+			require.NoError(t, stream.Recv(line))
+			assert.Equal(t, text, line.Text)
+		}
+		require.NoError(t, stream.CloseSend())
+		assert.Equal(t, io.EOF, stream.Recv(&LogLine{}))
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		stream, err := client.NewStream(context.Background(), "LogService", "Fail")
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&TailRequest{}))
+
+		line := &LogLine{}
+		require.NoError(t, stream.Recv(line))
+		assert.Equal(t, "partial", line.Text)
+
+		err = stream.Recv(line)
+		assert.ErrorIs(t, err, ErrInvalidParam)
+		assert.Contains(t, err.Error(), "bad request")
+	})
+
+	t.Run("method not found", func(t *testing.T) {
+		stream, err := client.NewStream(context.Background(), "BenchService", "Echo")
+		require.NoError(t, err)
+		assert.ErrorIs(t, stream.Recv(&LogLine{}), ErrNotFound)
+
+		_, err = client.Call("LogService", "Tail", &TailRequest{})
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := client.NewStream(ctx, "LogService", "Follow")
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&TailRequest{}))
+
+		line := &LogLine{}
+		require.NoError(t, stream.Recv(line))
+		cancel()
+
+		select {
+		case <-service.canceled:
+		case <-time.After(2 * time.Second):
+			t.Fatal("server stream was not canceled")
+		}
+		assert.ErrorIs(t, stream.Recv(line), ErrCanceled)
+
+		// 取消流后连接仍可继续使用
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "after"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"after"}`, string(data))
+	})
+}
+
+func TestStreamLimits(t *testing.T) {
+	t.Run("bounded receive buffer", func(t *testing.T) {
+		buf := newStreamBuffer()
+		for i := 0; i < streamBufferSize; i++ {
+			require.True(t, buf.put(&protocol.Message{}, nil))
+		}
+
+		// 队列已满时put等待消费者取出消息
+		put := make(chan bool, 1)
+		go func() {
+			put <- buf.put(&protocol.Message{}, nil)
+		}()
+		select {
+		case <-put:
+			t.Fatal("put should block when the buffer is full")
+		case <-time.After(50 * time.Millisecond):
+		}
+		_, err := buf.get(context.Background())
+		require.NoError(t, err)
+		assert.True(t, <-put)
+
+		// 队列终止或连接关闭时等待中的put返回false
+		go func() {
+			put <- buf.put(&protocol.Message{}, nil)
+		}()
+		time.Sleep(20 * time.Millisecond)
+		buf.close(ErrStreamClosed)
+		assert.False(t, <-put)
+
+		full := newStreamBuffer()
+		for i := 0; i < streamBufferSize; i++ {
+			require.True(t, full.put(&protocol.Message{}, nil))
+		}
+		done := make(chan struct{})
+		close(done)
+		assert.False(t, full.put(&protocol.Message{}, done))
+	})
+
+	t.Run("max concurrent streams", func(t *testing.T) {
+		service := &LogService{canceled: make(chan struct{})}
+		server := NewServer(WithMaxConcurrentStreams(1))
+		require.NoError(t, server.RegisterService(service))
+		addr := startTestServer(t, server)
+
+		client, err := NewClient(addr)
+		require.NoError(t, err)
+		defer client.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first, err := client.NewStream(ctx, "LogService", "Follow")
+		require.NoError(t, err)
+		require.NoError(t, first.Send(&TailRequest{}))
+		require.NoError(t, first.Recv(&LogLine{}))
+
+		// 超出上限的流被拒绝
+		second, err := client.NewStream(context.Background(), "LogService", "Tail")
+		require.NoError(t, err)
+		assert.ErrorIs(t, second.Recv(&LogLine{}), ErrResourceExhausted)
+
+		// 已有的流结束后可以打开新的流
+		cancel()
+		<-service.canceled
+		require.Eventually(t, func() bool {
+			stream, err := client.NewStream(context.Background(), "LogService", "Tail")
+			if err != nil || stream.Send(&TailRequest{Lines: 1}) != nil {
+				return false
+			}
+			return stream.Recv(&LogLine{}) == nil
+		}, time.Second, 20*time.Millisecond)
+	})
+}
@@ -25,3 +25,24 @@ func (s *UserServiceImpl) GetById(ctx context.Context, req *GetByIdReq) (*GetByI
 	}
 	return &GetByIdResp{}, nil
 }
+
+// TestUserService 使用Protobuf消息的用户服务
+type TestUserService struct {
+	GetTestById func(ctx context.Context, req *GetTestByIdReq) (*GetTestByIdResp, error)
+}
+
+// TestUserServiceImpl Protobuf用户服务实现
+type TestUserServiceImpl struct{}
+
+// GetTestById 获取用户信息
+func (s *TestUserServiceImpl) GetTestById(ctx context.Context, req *GetTestByIdReq) (*GetTestByIdResp, error) {
+	if req.Id == 123 {
+		return &GetTestByIdResp{
+			User: &TestUser{
+				Id:   req.Id,
+				Name: "test",
+			},
+		}, nil
+	}
+	return &GetTestByIdResp{}, nil
+}
@@ -0,0 +1,174 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/utils"
+)
+
+// TLSConfig 基于证书文件的TLS配置
+type TLSConfig struct {
+	CertFile string // 证书文件，客户端为空时不提供客户端证书
+	KeyFile  string // 私钥文件
+	// CAFile 服务端用于验证客户端证书，客户端用于验证服务端证书；客户端为空时使用系统根证书
+	CAFile string
+	// ServerName 客户端发送的SNI以及校验服务端证书使用的名称，为空时使用地址中的主机名
+	ServerName string
+	// ClientAuth 服务端验证客户端证书的策略，例如tls.VerifyClientCertIfGiven或tls.RequireAndVerifyClientCert
+	ClientAuth tls.ClientAuthType
+	// InsecureSkipVerify 客户端不验证服务端证书，仅用于测试
+	InsecureSkipVerify bool
+}
+
+// NewServerTLSConfig 根据证书文件创建服务端TLS配置
+// 证书文件更新后新建立的连接会使用新证书，不需要重启服务器
+func NewServerTLSConfig(cfg *TLSConfig) (*tls.Config, error) {
+	reloader, err := NewCertReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+		ClientAuth:     cfg.ClientAuth,
+		MinVersion:     tls.VersionTLS12,
+	}
+	if cfg.CAFile != "" {
+		pool, err := loadCertPool(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// NewClientTLSConfig 根据证书文件创建客户端TLS配置
+// 配置了CertFile时在服务端要求时提供客户端证书，证书文件更新后新建立的连接会使用新证书
+func NewClientTLSConfig(cfg *TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if cfg.CAFile != "" {
+		pool, err := loadCertPool(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" {
+		reloader, err := NewCertReloader(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = reloader.GetClientCertificate
+	}
+	return tlsConfig, nil
+}
+
+// loadCertPool 加载CA证书文件
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no valid certificates found in CA file %s", caFile)
+	}
+	return pool, nil
+}
+
+// CertReloader 在证书文件更新后自动重新加载证书
+// 每次TLS握手时检查文件修改时间，加载失败时继续使用之前的证书
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time // 已加载的证书和私钥文件中较新的修改时间
+}
+
+// NewCertReloader 加载证书并创建CertReloader
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 重新加载证书文件
+func (r *CertReloader) Reload() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate 用作tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.certificate(), nil
+}
+
+// GetClientCertificate 用作tls.Config.GetClientCertificate
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.certificate(), nil
+}
+
+// certificate 返回当前证书，文件有更新时先重新加载
+func (r *CertReloader) certificate() *tls.Certificate {
+	modTime, err := r.latestModTime()
+
+	r.mu.Lock()
+	changed := err == nil && modTime.After(r.modTime)
+	r.mu.Unlock()
+
+	if changed {
+		if err := r.Reload(); err != nil {
+			utils.Warn("Failed to reload certificate, keep using the previous one: %v", err)
+		} else {
+			utils.Info("Reloaded certificate from %s", r.certFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert
+}
+
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if keyInfo.ModTime().After(certInfo.ModTime()) {
+		return keyInfo.ModTime(), nil
+	}
+	return certInfo.ModTime(), nil
+}
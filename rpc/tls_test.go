@@ -0,0 +1,228 @@
+package rpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/cluster/failover"
+	"github.com/fyerfyer/fyer-rpc/naming"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PeerService 返回客户端证书名称和应用名称
+type PeerService struct{}
+
+func (s *PeerService) WhoAmI(ctx context.Context, req *BenchRequest) (*BenchResponse, error) {
+	peer, ok := PeerFromContext(ctx)
+	if !ok {
+		return nil, NewRPCError(ErrCodeInternal, "missing peer")
+	}
+	name := ""
+	if cert := peer.Certificate(); cert != nil {
+		name = cert.Subject.CommonName
+	}
+	return &BenchResponse{Value: name + "|" + peer.AppName}, nil
+}
+
+// testCA 测试用的证书签发机构
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	ca := &testCA{cert: cert, key: key, dir: t.TempDir()}
+	writePEM(t, ca.path("ca.pem"), "CERTIFICATE", der)
+	return ca
+}
+
+func (ca *testCA) path(name string) string {
+	return filepath.Join(ca.dir, name)
+}
+
+// issue 签发证书并写入name.pem和name-key.pem，返回两个文件的路径
+func (ca *testCA) issue(t *testing.T, name, commonName string, usage x509.ExtKeyUsage) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := ca.path(name+".pem"), ca.path(name+"-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+func TestTLS(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "server", "server-1", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "client", "order-client", x509.ExtKeyUsageClientAuth)
+
+	newServer := func(t *testing.T, clientAuth tls.ClientAuthType, configure ...func(*tls.Config)) string {
+		tlsConfig, err := NewServerTLSConfig(&TLSConfig{
+			CertFile:   serverCert,
+			KeyFile:    serverKey,
+			CAFile:     ca.path("ca.pem"),
+			ClientAuth: clientAuth,
+		})
+		require.NoError(t, err)
+		for _, f := range configure {
+			f(tlsConfig)
+		}
+		server := NewServer(WithServerTLSConfig(tlsConfig))
+		require.NoError(t, server.RegisterService(&PeerService{}))
+		return startTestServer(t, server)
+	}
+
+	clientConfig := func(t *testing.T, withCert bool) *tls.Config {
+		cfg := &TLSConfig{CAFile: ca.path("ca.pem")}
+		if withCert {
+			cfg.CertFile, cfg.KeyFile = clientCert, clientKey
+		}
+		tlsConfig, err := NewClientTLSConfig(cfg)
+		require.NoError(t, err)
+		return tlsConfig
+	}
+
+	t.Run("mutual tls", func(t *testing.T) {
+		addr := newServer(t, tls.RequireAndVerifyClientCert)
+
+		client, err := NewClient(addr, WithTLSConfig(clientConfig(t, true)), WithIdentity("order", ""))
+		require.NoError(t, err)
+		defer client.Close()
+
+		data, err := client.Call("PeerService", "WhoAmI", &BenchRequest{})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"order-client|order"}`, string(data))
+
+		// 未提供客户端证书或使用明文连接时无法建立连接
+		_, err = NewClient(addr, WithTLSConfig(clientConfig(t, false)), WithHandshakeTimeout(time.Second))
+		assert.ErrorIs(t, err, ErrUnavailable)
+		_, err = NewClient(addr, WithHandshakeTimeout(time.Second))
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+
+	t.Run("optional client certificate", func(t *testing.T) {
+		addr := newServer(t, tls.VerifyClientCertIfGiven)
+
+		client, err := NewClient(addr, WithTLSConfig(clientConfig(t, false)))
+		require.NoError(t, err)
+		defer client.Close()
+
+		data, err := client.Call("PeerService", "WhoAmI", &BenchRequest{})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"|"}`, string(data))
+	})
+
+	t.Run("server name", func(t *testing.T) {
+		var serverName atomic.Value
+		addr := newServer(t, tls.NoClientCert, func(cfg *tls.Config) {
+			getCertificate := cfg.GetCertificate
+			cfg.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+				serverName.Store(hello.ServerName)
+				return getCertificate(hello)
+			}
+		})
+
+		tlsConfig := clientConfig(t, false)
+		tlsConfig.ServerName = "localhost"
+		client, err := NewClient(addr, WithTLSConfig(tlsConfig))
+		require.NoError(t, err)
+		defer client.Close()
+		assert.Equal(t, "localhost", serverName.Load())
+
+		// 证书中不包含的名称无法通过校验
+		tlsConfig = clientConfig(t, false)
+		tlsConfig.ServerName = "example.com"
+		_, err = NewClient(addr, WithTLSConfig(tlsConfig))
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+
+	t.Run("reload certificate", func(t *testing.T) {
+		addr := newServer(t, tls.NoClientCert)
+
+		serverCommonName := func() string {
+			var name string
+			tlsConfig := clientConfig(t, false)
+			tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+				name = state.PeerCertificates[0].Subject.CommonName
+				return nil
+			}
+			client, err := NewClient(addr, WithTLSConfig(tlsConfig))
+			require.NoError(t, err)
+			client.Close()
+			return name
+		}
+		assert.Equal(t, "server-1", serverCommonName())
+
+		// 替换证书文件后新连接使用新证书
+		ca.issue(t, "server", "server-2", x509.ExtKeyUsageServerAuth)
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(serverCert, later, later))
+		assert.Equal(t, "server-2", serverCommonName())
+	})
+
+	t.Run("failover keeps tls", func(t *testing.T) {
+		addr := newServer(t, tls.NoClientCert)
+
+		client, err := NewClient(addr, WithTLSConfig(clientConfig(t, false)), WithFailover(failover.DefaultConfig))
+		require.NoError(t, err)
+		defer client.Close()
+
+		// 故障转移时建立的新连接同样使用TLS，明文连接会被只接受TLS的服务端拒绝
+		instances := []*naming.Instance{{ID: "tls-1", Address: addr, Status: naming.StatusEnabled}}
+		data, err := client.CallWithFailover(context.Background(), "PeerService", "WhoAmI", &BenchRequest{}, instances)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"|"}`, string(data))
+	})
+}
@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-rpc/cluster/failover"
+	"github.com/fyerfyer/fyer-rpc/naming"
+	"github.com/fyerfyer/fyer-rpc/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransports(t *testing.T) {
+	echo := func(t *testing.T, client *Client) {
+		data, err := client.Call("BenchService", "Echo", &BenchRequest{Value: "local"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"local"}`, string(data))
+	}
+
+	t.Run("unix socket", func(t *testing.T) {
+		server := NewServer()
+		require.NoError(t, server.RegisterService(&BenchService{}))
+		address := "unix://" + filepath.Join(t.TempDir(), "svc.sock")
+
+		listener, err := transport.Listen(address)
+		require.NoError(t, err)
+		go server.Serve(listener)
+		defer server.Shutdown(context.Background())
+
+		client, err := NewClient(address)
+		require.NoError(t, err)
+		defer client.Close()
+		echo(t, client)
+	})
+
+	t.Run("in-process pipe", func(t *testing.T) {
+		server := NewServer()
+		require.NoError(t, server.RegisterService(&BenchService{}))
+		inproc := transport.NewInproc()
+		listener, err := inproc.Listen("bench")
+		require.NoError(t, err)
+		go server.Serve(listener)
+		defer server.Shutdown(context.Background())
+
+		client, err := NewClient("bench", WithDialer(inproc))
+		require.NoError(t, err)
+		defer client.Close()
+		echo(t, client)
+
+		// 连接池创建的连接同样使用自定义的连接方式
+		pool := NewConnPool("bench", 2, 0, WithClientOptions(WithDialer(inproc)))
+		defer pool.Close()
+		pooled, err := pool.Get()
+		require.NoError(t, err)
+		echo(t, pooled)
+		pool.Put(pooled)
+
+		// 故障转移时连接其他实例也使用自定义的连接方式
+		failoverClient, err := NewClient("bench", WithDialer(inproc), WithFailover(failover.DefaultConfig))
+		require.NoError(t, err)
+		defer failoverClient.Close()
+		instances := []*naming.Instance{{ID: "bench-1", Address: "bench", Status: naming.StatusEnabled}}
+		data, err := failoverClient.CallWithFailover(context.Background(), "BenchService", "Echo", &BenchRequest{Value: "local"}, instances)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Value":"local"}`, string(data))
+	})
+
+	t.Run("inproc scheme", func(t *testing.T) {
+		server := NewServer()
+		require.NoError(t, server.RegisterService(&BenchService{}))
+		go server.Start("inproc://rpc-bench")
+		defer server.Shutdown(context.Background())
+
+		require.Eventually(t, func() bool {
+			client, err := NewClient("inproc://rpc-bench")
+			if err != nil {
+				return false
+			}
+			defer client.Close()
+			echo(t, client)
+			return true
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("unknown scheme", func(t *testing.T) {
+		_, err := NewClient("quic://127.0.0.1:1")
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.ErrorIs(t, err, transport.ErrUnknownScheme)
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"reflect"
+
+	"github.com/fyerfyer/fyer-rpc/protocol"
 )
 
 // Request 表示 RPC 请求
@@ -22,7 +24,10 @@ type Response struct {
 // ServiceDesc 描述服务的元数据
 type ServiceDesc struct {
 	ServiceName string
+	Version     string
+	Info        *ServiceInfo
 	Methods     map[string]reflect.Method
+	Rejected    map[string]error // 不符合RPC方法签名而被跳过的方法及原因
 	Instance    any
 }
 
@@ -99,20 +104,46 @@ func (v *InterfaceMethodValidator) ValidateMethod(method reflect.Method) bool {
 }
 
 // RPCError 自定义RPC错误类型
+// 服务端处理器返回的RPCError会连同错误码和详情一起传递给客户端
 type RPCError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int                     `json:"code"`
+	Message string                  `json:"message"`
+	Details []*protocol.ErrorDetail `json:"details,omitempty"`
+	cause   error                   // 本地产生错误时的底层原因，不参与传输
 }
 
 func (e *RPCError) Error() string {
+	if e.Message == "" {
+		return CodeText(e.Code)
+	}
 	return e.Message
 }
 
+// Is 错误码相同且目标错误信息为空或一致时视为同一错误，
+// 因此 errors.Is(err, rpc.ErrNotFound) 可以匹配任意未找到错误
+func (e *RPCError) Is(target error) bool {
+	t, ok := target.(*RPCError)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code && (t.Message == "" || t.Message == e.Message)
+}
+
+// Unwrap 返回底层原因
+func (e *RPCError) Unwrap() error {
+	return e.cause
+}
+
 // 预定义错误码
 const (
-	ErrCodeInternal     = 1000 // 内部错误
-	ErrCodeInvalidParam = 1001 // 无效参数
-	ErrCodeNotFound     = 1002 // 服务/方法未找到
+	ErrCodeInternal          = 1000 // 内部错误
+	ErrCodeInvalidParam      = 1001 // 无效参数
+	ErrCodeNotFound          = 1002 // 服务/方法未找到
+	ErrCodeTimeout           = 1003 // 调用超时
+	ErrCodeCanceled          = 1004 // 调用被取消
+	ErrCodeUnavailable       = 1005 // 服务不可用，例如服务器正在关闭
+	ErrCodeResourceExhausted = 1006 // 资源耗尽，例如消息超过大小限制
+	ErrCodeUnauthenticated   = 1007 // 未通过认证
 )
 
 // NewRPCError 创建新的RPC错误
@@ -122,3 +153,12 @@ func NewRPCError(code int, message string) *RPCError {
 		Message: message,
 	}
 }
+
+// WrapRPCError 创建包装底层原因的RPC错误，可通过 errors.Is/errors.As 访问原因
+func WrapRPCError(code int, message string, cause error) *RPCError {
+	return &RPCError{
+		Code:    code,
+		Message: message + ": " + cause.Error(),
+		cause:   cause,
+	}
+}
@@ -0,0 +1,89 @@
+package rpc
+
+import "sync"
+
+// workerPool 固定数量的工作协程，负责执行服务端请求
+// 同时处于排队和执行中的任务数不超过maxConcurrent
+type workerPool struct {
+	size     int
+	tasks    chan func()
+	sem      chan struct{} // 并发许可，控制排队与执行中的任务总数
+	quit     chan struct{}
+	once     sync.Once
+	stopOnce sync.Once
+}
+
+func newWorkerPool(size, maxConcurrent int) *workerPool {
+	if size <= 0 {
+		size = 1
+	}
+	if maxConcurrent <= 0 {
+		maxConcurrent = size
+	}
+
+	return &workerPool{
+		size:  size,
+		tasks: make(chan func(), maxConcurrent),
+		sem:   make(chan struct{}, maxConcurrent),
+		quit:  make(chan struct{}),
+	}
+}
+
+// start 启动工作协程，重复调用只生效一次
+func (p *workerPool) start() {
+	p.once.Do(func() {
+		for i := 0; i < p.size; i++ {
+			go p.worker()
+		}
+	})
+}
+
+func (p *workerPool) worker() {
+	for {
+		select {
+		case task := <-p.tasks:
+			task()
+			<-p.sem
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// submit 提交任务，达到并发上限时阻塞等待，形成对读循环的背压
+// 协程池停止后返回false，任务不会被执行
+func (p *workerPool) submit(task func()) bool {
+	// 许可有空余时select可能在quit已关闭的情况下仍选中sem，先确认协程池未停止
+	if p.stopped() {
+		return false
+	}
+	select {
+	case p.sem <- struct{}{}:
+	case <-p.quit:
+		return false
+	}
+	if p.stopped() {
+		<-p.sem
+		return false
+	}
+
+	p.tasks <- task
+	return true
+}
+
+// stopped 判断协程池是否已停止
+func (p *workerPool) stopped() bool {
+	select {
+	case <-p.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop 停止工作协程，尚未执行的任务被丢弃
+func (p *workerPool) stop() {
+	p.stopOnce.Do(func() {
+		close(p.quit)
+	})
+}
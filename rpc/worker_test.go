@@ -0,0 +1,18 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPoolStopped(t *testing.T) {
+	pool := newWorkerPool(1, 4)
+	pool.start()
+	pool.stop()
+
+	// 协程池停止后即使许可有空余也不再接受任务
+	for i := 0; i < 100; i++ {
+		assert.False(t, pool.submit(func() {}))
+	}
+}
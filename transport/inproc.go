@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// ErrListenerClosed 进程内监听器已关闭，与net.ErrClosed匹配
+var ErrListenerClosed = fmt.Errorf("inproc listener closed: %w", net.ErrClosed)
+
+// Inproc 进程内管道传输方式，连接不经过网络，适用于单元测试和同一进程内的调用
+// 每个名称同一时间只能有一个监听器
+type Inproc struct {
+	mu        sync.Mutex
+	listeners map[string]*inprocListener
+}
+
+var defaultInproc = NewInproc()
+
+// NewInproc 创建独立命名空间的进程内传输方式
+func NewInproc() *Inproc {
+	return &Inproc{listeners: make(map[string]*inprocListener)}
+}
+
+// Listen 以name注册监听器
+func (p *Inproc) Listen(name string) (net.Listener, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.listeners[name]; ok {
+		return nil, fmt.Errorf("inproc address already in use: %s", name)
+	}
+	l := &inprocListener{
+		owner: p,
+		addr:  inprocAddr(name),
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+	p.listeners[name] = l
+	return l, nil
+}
+
+// Dial 连接到名为name的监听器，等待监听器接受连接直到ctx结束
+func (p *Inproc) Dial(ctx context.Context, name string) (net.Conn, error) {
+	p.mu.Lock()
+	l, ok := p.listeners[name]
+	p.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("inproc: no listener on %s", name)
+	}
+
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.done:
+		client.Close()
+		server.Close()
+		return nil, ErrListenerClosed
+	case <-ctx.Done():
+		client.Close()
+		server.Close()
+		return nil, ctx.Err()
+	}
+}
+
+func (p *Inproc) remove(name string, l *inprocListener) {
+	p.mu.Lock()
+	if p.listeners[name] == l {
+		delete(p.listeners, name)
+	}
+	p.mu.Unlock()
+}
+
+// inprocListener 进程内监听器
+type inprocListener struct {
+	owner     *Inproc
+	addr      inprocAddr
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *inprocListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, ErrListenerClosed
+	}
+}
+
+func (l *inprocListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		l.owner.remove(string(l.addr), l)
+	})
+	return nil
+}
+
+func (l *inprocListener) Addr() net.Addr {
+	return l.addr
+}
+
+// inprocAddr 进程内地址
+type inprocAddr string
+
+func (a inprocAddr) Network() string {
+	return SchemeInproc
+}
+
+func (a inprocAddr) String() string {
+	return string(a)
+}
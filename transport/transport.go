@@ -0,0 +1,119 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownScheme 地址使用了未注册的传输方式
+var ErrUnknownScheme = errors.New("unknown transport scheme")
+
+// Dialer 建立到服务端的连接
+type Dialer interface {
+	// Dial 连接到address，address不包含传输方式前缀
+	Dial(ctx context.Context, address string) (net.Conn, error)
+}
+
+// Transport 传输方式，负责建立客户端连接和创建服务端监听器
+type Transport interface {
+	Dialer
+	// Listen 在address上监听，address不包含传输方式前缀
+	Listen(address string) (net.Listener, error)
+}
+
+// 内置传输方式
+const (
+	SchemeTCP    = "tcp"    // TCP，未指定传输方式的地址默认使用
+	SchemeUnix   = "unix"   // Unix域套接字，例如unix:///run/svc.sock
+	SchemeInproc = "inproc" // 进程内管道，例如inproc://order
+)
+
+var (
+	mu         sync.RWMutex
+	transports = map[string]Transport{
+		SchemeTCP:    TCP{},
+		SchemeUnix:   Unix{},
+		SchemeInproc: defaultInproc,
+	}
+)
+
+// Register 注册传输方式，已存在时覆盖
+func Register(scheme string, t Transport) {
+	mu.Lock()
+	defer mu.Unlock()
+	transports[scheme] = t
+}
+
+// Get 获取已注册的传输方式
+func Get(scheme string) (Transport, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	t, ok := transports[scheme]
+	return t, ok
+}
+
+// Parse 拆分地址中的传输方式和传输层地址，未指定传输方式时使用TCP
+// 例如"unix:///run/svc.sock"拆分为"unix"和"/run/svc.sock"，"127.0.0.1:8000"拆分为"tcp"和"127.0.0.1:8000"
+func Parse(address string) (scheme, addr string) {
+	if i := strings.Index(address, "://"); i > 0 {
+		return address[:i], address[i+3:]
+	}
+	return SchemeTCP, address
+}
+
+// Resolve 根据地址的传输方式返回对应的Transport和传输层地址
+func Resolve(address string) (Transport, string, error) {
+	scheme, addr := Parse(address)
+	t, ok := Get(scheme)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnknownScheme, scheme)
+	}
+	return t, addr, nil
+}
+
+// Dial 按地址的传输方式建立连接
+func Dial(ctx context.Context, address string) (net.Conn, error) {
+	t, addr, err := Resolve(address)
+	if err != nil {
+		return nil, err
+	}
+	return t.Dial(ctx, addr)
+}
+
+// Listen 按地址的传输方式创建监听器
+func Listen(address string) (net.Listener, error) {
+	t, addr, err := Resolve(address)
+	if err != nil {
+		return nil, err
+	}
+	return t.Listen(addr)
+}
+
+// TCP 基于TCP的传输方式
+type TCP struct{}
+
+func (TCP) Dial(ctx context.Context, address string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", address)
+}
+
+func (TCP) Listen(address string) (net.Listener, error) {
+	return net.Listen("tcp", address)
+}
+
+// Unix 基于Unix域套接字的传输方式，适用于同一主机上的进程间通信
+type Unix struct{}
+
+func (Unix) Dial(ctx context.Context, address string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "unix", address)
+}
+
+// Listen 监听Unix域套接字，监听器关闭时删除套接字文件
+func (Unix) Listen(address string) (net.Listener, error) {
+	return net.Listen("unix", address)
+}
@@ -0,0 +1,114 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		address string
+		scheme  string
+		addr    string
+	}{
+		{"127.0.0.1:8000", SchemeTCP, "127.0.0.1:8000"},
+		{"tcp://localhost:8000", SchemeTCP, "localhost:8000"},
+		{"unix:///run/svc.sock", SchemeUnix, "/run/svc.sock"},
+		{"inproc://order", SchemeInproc, "order"},
+	}
+	for _, tt := range tests {
+		scheme, addr := Parse(tt.address)
+		assert.Equal(t, tt.scheme, scheme, tt.address)
+		assert.Equal(t, tt.addr, addr, tt.address)
+	}
+
+	_, err := Dial(context.Background(), "quic://localhost:8000")
+	assert.ErrorIs(t, err, ErrUnknownScheme)
+}
+
+// echo 在监听器上接受一个连接并原样返回收到的数据
+func echo(t *testing.T, l net.Listener) {
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 64)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		conn.Write(buf[:n])
+	}()
+}
+
+func roundTrip(t *testing.T, address string) {
+	l, err := Listen(address)
+	require.NoError(t, err)
+	defer l.Close()
+	echo(t, l)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	// 使用监听器的实际地址，TCP监听0端口时由系统分配端口
+	scheme, _ := Parse(address)
+	conn, err := Dial(ctx, scheme+"://"+l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+}
+
+func TestTransports(t *testing.T) {
+	t.Run("tcp", func(t *testing.T) {
+		roundTrip(t, "tcp://127.0.0.1:0")
+	})
+
+	t.Run("unix", func(t *testing.T) {
+		roundTrip(t, "unix://"+filepath.Join(t.TempDir(), "svc.sock"))
+	})
+
+	t.Run("inproc", func(t *testing.T) {
+		roundTrip(t, "inproc://echo")
+	})
+}
+
+func TestInproc(t *testing.T) {
+	p := NewInproc()
+
+	_, err := p.Dial(context.Background(), "missing")
+	assert.Error(t, err)
+
+	l, err := p.Listen("svc")
+	require.NoError(t, err)
+	_, err = p.Listen("svc")
+	assert.Error(t, err, "name already in use")
+
+	// 没有调用Accept时Dial等待直到ctx结束
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = p.Dial(ctx, "svc")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, l.Close())
+	_, err = l.Accept()
+	assert.True(t, errors.Is(err, net.ErrClosed))
+
+	// 关闭后名称可以重新使用
+	l, err = p.Listen("svc")
+	require.NoError(t, err)
+	l.Close()
+}
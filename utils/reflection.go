@@ -25,7 +25,8 @@ func IsExported(name string) bool {
 }
 
 // ValidateMethod 验证方法是否符合RPC方法签名
-// 符合的签名为: func(ctx context.Context, req *Request) (*Response, error)
+// 支持的签名为: func([ctx context.Context,] req Request) ([Response,] error)
+// 请求和响应可以是结构体、结构体指针、切片、映射或基本类型，不能是接口、函数或通道
 func ValidateMethod(method reflect.Method) error {
 	// 检查方法名称是否已导出
 	if !IsExported(method.Name) {
@@ -33,89 +34,225 @@ func ValidateMethod(method reflect.Method) error {
 	}
 
 	// 检查方法参数数量
-	if method.Type.NumIn() != 3 { // receiver + ctx + request
-		return fmt.Errorf("%s: %w: expected 3 arguments, got %d", method.Name, ErrInvalidMethod, method.Type.NumIn())
+	numIn := method.Type.NumIn()
+	if numIn != 2 && numIn != 3 { // receiver + [ctx] + request
+		return fmt.Errorf("%s: %w: expected 2 or 3 arguments, got %d", method.Name, ErrInvalidMethod, numIn)
+	}
+	if numIn == 3 && method.Type.In(1) != contextType {
+		return fmt.Errorf("%s: %w: first parameter must be context.Context", method.Name, ErrInvalidArgument)
+	}
+	reqType := method.Type.In(numIn - 1)
+	if reqType == contextType {
+		return fmt.Errorf("%s: %w: missing request parameter", method.Name, ErrInvalidArgument)
+	}
+	if err := validatePayloadType(reqType); err != nil {
+		return fmt.Errorf("%s: %w: request %v", method.Name, ErrInvalidArgument, err)
 	}
 
-	// 检查返回值数量
-	if method.Type.NumOut() != 2 { // response + error
-		return fmt.Errorf("%s: %w: expected 2 return values, got %d", method.Name, ErrInvalidMethod, method.Type.NumOut())
+	// 检查返回值
+	numOut := method.Type.NumOut()
+	if numOut != 1 && numOut != 2 { // [response] + error
+		return fmt.Errorf("%s: %w: expected 1 or 2 return values, got %d", method.Name, ErrInvalidMethod, numOut)
+	}
+	if method.Type.Out(numOut-1) != errorType {
+		return fmt.Errorf("%s: %w: last return value must be error", method.Name, ErrInvalidArgument)
+	}
+	if numOut == 2 {
+		if err := validatePayloadType(method.Type.Out(0)); err != nil {
+			return fmt.Errorf("%s: %w: response %v", method.Name, ErrInvalidArgument, err)
+		}
 	}
 
-	// 检查第一个参数是否为 context.Context
-	if !method.Type.In(1).Implements(reflect.TypeOf((*context.Context)(nil)).Elem()) {
-		return fmt.Errorf("%s: %w: first parameter must be context.Context", method.Name, ErrInvalidArgument)
+	return nil
+}
+
+// validatePayloadType 检查类型能否作为请求或响应被序列化
+func validatePayloadType(typ reflect.Type) error {
+	base := typ
+	if base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	switch base.Kind() {
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Ptr:
+		return fmt.Errorf("type %v cannot be serialized", typ)
 	}
+	return nil
+}
 
-	// 检查第二个参数是否为指针类型
-	if method.Type.In(2).Kind() != reflect.Ptr {
-		return fmt.Errorf("%s: %w: second parameter must be a pointer", method.Name, ErrInvalidArgument)
+// Stream 流式方法的流参数需要实现的最小接口
+type Stream interface {
+	SendMsg(m interface{}) error
+	RecvMsg(m interface{}) error
+}
+
+var (
+	streamType  = reflect.TypeOf((*Stream)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+)
+
+// ValidateStreamMethod 验证方法是否符合流式RPC方法签名
+// 服务端流的签名为: func(req *Request, stream Stream) error
+// 客户端流和双向流的签名为: func(stream Stream) error
+func ValidateStreamMethod(method reflect.Method) error {
+	if !IsExported(method.Name) {
+		return fmt.Errorf("%s: %w", method.Name, ErrNotExported)
+	}
+
+	numIn := method.Type.NumIn()
+	if numIn != 2 && numIn != 3 { // receiver + [request] + stream
+		return fmt.Errorf("%s: %w: expected 2 or 3 arguments, got %d", method.Name, ErrInvalidMethod, numIn)
+	}
+	if method.Type.NumOut() != 1 {
+		return fmt.Errorf("%s: %w: expected 1 return value, got %d", method.Name, ErrInvalidMethod, method.Type.NumOut())
 	}
 
-	// 检查返回值类型
-	if method.Type.Out(0).Kind() != reflect.Ptr {
-		return fmt.Errorf("%s: %w: first return value must be a pointer", method.Name, ErrInvalidArgument)
+	// 最后一个参数为流
+	if !method.Type.In(numIn - 1).Implements(streamType) {
+		return fmt.Errorf("%s: %w: last parameter must be a stream", method.Name, ErrInvalidArgument)
 	}
-	if !method.Type.Out(1).Implements(reflect.TypeOf((*error)(nil)).Elem()) {
-		return fmt.Errorf("%s: %w: second return value must be error", method.Name, ErrInvalidArgument)
+	if numIn == 3 && method.Type.In(1).Kind() != reflect.Ptr {
+		return fmt.Errorf("%s: %w: request parameter must be a pointer", method.Name, ErrInvalidArgument)
+	}
+	if method.Type.Out(0) != errorType {
+		return fmt.Errorf("%s: %w: return value must be error", method.Name, ErrInvalidArgument)
 	}
 
 	return nil
 }
 
-// GetServiceMethods 获取服务的所有符合RPC方法签名的方法
+// IsStreamMethod 判断方法是否为流式方法
+func IsStreamMethod(method reflect.Method) bool {
+	numIn := method.Type.NumIn()
+	return numIn >= 2 && method.Type.In(numIn-1).Implements(streamType)
+}
+
+// GetServiceMethods 获取服务的所有符合RPC方法签名的方法，包括流式方法
+// 不符合签名的方法被跳过，跳过的原因记录在日志中
 func GetServiceMethods(service interface{}) (map[string]reflect.Method, error) {
+	methods, rejected, err := ScanServiceMethods(service)
+	if err != nil {
+		return nil, err
+	}
+	for name, reason := range rejected {
+		Info("Skipping invalid method %s: %v", name, reason)
+	}
+	return methods, nil
+}
+
+// ScanServiceMethods 检查服务的所有导出方法，分别返回符合RPC方法签名的方法和被拒绝的方法及其原因
+func ScanServiceMethods(service interface{}) (methods map[string]reflect.Method, rejected map[string]error, err error) {
 	serviceValue := reflect.ValueOf(service)
 	if serviceValue.Kind() != reflect.Ptr {
-		return nil, errors.New("service must be a pointer")
+		return nil, nil, errors.New("service must be a pointer")
 	}
 
 	serviceType := serviceValue.Type()
-	methods := make(map[string]reflect.Method)
+	methods = make(map[string]reflect.Method)
+	rejected = make(map[string]error)
 
 	for i := 0; i < serviceType.NumMethod(); i++ {
 		method := serviceType.Method(i)
-		err := ValidateMethod(method)
+		var err error
+		if IsStreamMethod(method) {
+			err = ValidateStreamMethod(method)
+		} else {
+			err = ValidateMethod(method)
+		}
 		if err != nil {
-			// 使用当前包内的Debug函数，而不是外部依赖
-			Info("Skipping invalid method %s: %v", method.Name, err)
+			rejected[method.Name] = err
 			continue
 		}
 		methods[method.Name] = method
 	}
 
-	return methods, nil
+	return methods, rejected, nil
 }
 
-// InvokeMethod 调用服务方法
+// InvokeMethod 调用服务方法，arg可以是请求类型的值或指向请求的指针
+// 只返回error的方法响应为nil
 func InvokeMethod(ctx context.Context, instance interface{}, method reflect.Method, arg interface{}) (interface{}, error) {
 	// 创建参数列表
-	args := make([]reflect.Value, 3)
-	args[0] = reflect.ValueOf(instance) // 服务实例
-	args[1] = reflect.ValueOf(ctx)      // 上下文
-	args[2] = reflect.ValueOf(arg)      // 请求参数
+	numIn := method.Type.NumIn()
+	args := make([]reflect.Value, 0, numIn)
+	args = append(args, reflect.ValueOf(instance)) // 服务实例
+	if numIn == 3 {
+		args = append(args, reflect.ValueOf(ctx)) // 上下文
+	}
+	args = append(args, requestValue(method.Type.In(numIn-1), arg)) // 请求参数
 
 	// 调用方法
 	results := method.Func.Call(args)
 
 	// 处理错误返回值
-	if !results[1].IsNil() {
-		err := results[1].Interface().(error)
-		return nil, err
+	if errValue := results[len(results)-1]; !errValue.IsNil() {
+		return nil, errValue.Interface().(error)
+	}
+	if len(results) == 1 {
+		return nil, nil
 	}
 
 	// 返回响应
 	return results[0].Interface(), nil
 }
 
-// GetRequestType 获取方法的请求参数类型
+// requestValue 将请求参数转换为方法需要的类型，解码得到的指针在方法接收值类型时解引用
+func requestValue(typ reflect.Type, arg interface{}) reflect.Value {
+	if arg == nil {
+		return reflect.Zero(typ)
+	}
+	value := reflect.ValueOf(arg)
+	if value.Type() != typ && value.Kind() == reflect.Ptr && value.Type().Elem() == typ {
+		if value.IsNil() {
+			return reflect.Zero(typ)
+		}
+		return value.Elem()
+	}
+	return value
+}
+
+// InvokeStreamMethod 调用流式方法，客户端流和双向流方法的req为nil
+func InvokeStreamMethod(instance interface{}, method reflect.Method, req interface{}, stream Stream) error {
+	args := []reflect.Value{reflect.ValueOf(instance)}
+	if req != nil {
+		args = append(args, reflect.ValueOf(req))
+	}
+	args = append(args, reflect.ValueOf(stream))
+
+	results := method.Func.Call(args)
+	if !results[0].IsNil() {
+		return results[0].Interface().(error)
+	}
+	return nil
+}
+
+// GetRequestType 获取方法的请求参数类型，请求为指针时返回其指向的类型
 func GetRequestType(method reflect.Method) reflect.Type {
-	return method.Type.In(2).Elem()
+	return indirect(method.Type.In(method.Type.NumIn() - 1))
 }
 
-// GetResponseType 获取方法的响应返回值类型
+// GetStreamRequestType 获取服务端流方法的请求参数类型，客户端流和双向流方法返回nil
+func GetStreamRequestType(method reflect.Method) reflect.Type {
+	if method.Type.NumIn() != 3 {
+		return nil
+	}
+	return method.Type.In(1).Elem()
+}
+
+// GetResponseType 获取方法的响应类型，响应为指针时返回其指向的类型，只返回error的方法返回nil
 func GetResponseType(method reflect.Method) reflect.Type {
-	return method.Type.Out(0).Elem()
+	if method.Type.NumOut() != 2 {
+		return nil
+	}
+	return indirect(method.Type.Out(0))
+}
+
+// indirect 返回指针指向的类型，非指针类型原样返回
+func indirect(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Ptr {
+		return typ.Elem()
+	}
+	return typ
 }
 
 // CreateInstance 创建指定类型的实例